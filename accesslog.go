@@ -0,0 +1,133 @@
+package celeritas
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// AccessLogConfig holds the settings for the access-log middleware.
+type AccessLogConfig struct {
+	// Format is common, combined, json or off.
+	Format string `env:"ACCESS_LOG_FORMAT" default:"combined"`
+
+	// Exclude lists paths that are never logged. A trailing * matches any
+	// path with that prefix, for example /public/*.
+	Exclude []string `env:"ACCESS_LOG_EXCLUDE"`
+
+	// SampleRate is the fraction of requests logged, from 0 to 1.
+	SampleRate float64 `env:"ACCESS_LOG_SAMPLE_RATE" default:"1"`
+}
+
+// validate checks the access-log settings.
+func (a AccessLogConfig) validate() []string {
+	var problems []string
+	switch a.Format {
+	case "common", "combined", "json", "off":
+	default:
+		problems = append(problems, fmt.Sprintf(
+			"ACCESS_LOG_FORMAT: must be one of common, combined, json, off (got %q)", a.Format))
+	}
+	if a.SampleRate < 0 || a.SampleRate > 1 {
+		problems = append(problems, fmt.Sprintf("ACCESS_LOG_SAMPLE_RATE: must be between 0 and 1 (got %v)", a.SampleRate))
+	}
+	return problems
+}
+
+// excluded reports whether requests for urlPath should not be logged.
+func (a AccessLogConfig) excluded(urlPath string) bool {
+	for _, pattern := range a.Exclude {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(urlPath, prefix) {
+				return true
+			}
+		} else if urlPath == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessLog is middleware that writes one record per request through the
+// application logger. Records include the method, path, status, response
+// size, latency, client address and request ID, formatted as Common Log
+// Format, Combined Log Format or structured JSON attributes according to
+// ACCESS_LOG_FORMAT.
+//
+// It should run after chi's RequestID and RealIP middleware so the request ID
+// and real client address are available.
+func (c *Celeritas) AccessLog(next http.Handler) http.Handler {
+	cfg := c.config.AccessLog
+	logger := c.logger()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.Format == "off" || cfg.excluded(r.URL.Path) ||
+			(cfg.SampleRate < 1 && rand.Float64() >= cfg.SampleRate) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+		latency := time.Since(start)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		if cfg.Format == "json" {
+			logger.LogAttrs(r.Context(), slog.LevelInfo, "request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.RequestURI()),
+				slog.String("proto", r.Proto),
+				slog.Int("status", status),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.Duration("latency", latency),
+				slog.String("remote_ip", remoteIP(r)),
+				slog.String("user_agent", r.UserAgent()),
+			)
+			return
+		}
+
+		line := commonLogLine(r, status, ww.BytesWritten(), start)
+		if cfg.Format == "combined" {
+			line = fmt.Sprintf("%s %q %q", line, r.Referer(), r.UserAgent())
+		}
+		logger.LogAttrs(r.Context(), slog.LevelInfo, line, slog.Duration("latency", latency))
+	})
+}
+
+// commonLogLine formats a request in Common Log Format.
+func commonLogLine(r *http.Request, status, size int, start time.Time) string {
+	bytes := "-"
+	if size > 0 {
+		bytes = fmt.Sprint(size)
+	}
+	return fmt.Sprintf(`%s - - [%s] "%s %s %s" %d %s`,
+		remoteIP(r),
+		start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method,
+		r.URL.RequestURI(),
+		r.Proto,
+		status,
+		bytes,
+	)
+}
+
+// remoteIP returns the client address without its port. After chi's RealIP
+// middleware, RemoteAddr holds the address from X-Real-IP or X-Forwarded-For.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,110 @@
+package celeritas
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestCeleritas_AccessLog(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      AccessLogConfig
+		path     string
+		wantMsg  string
+		wantAttr map[string]any
+		wantNone bool
+	}{
+		{
+			name:    "common log format",
+			cfg:     AccessLogConfig{Format: "common", SampleRate: 1},
+			path:    "/users?page=2",
+			wantMsg: `203.0.113.9 - - [`,
+		},
+		{
+			name:    "combined log format",
+			cfg:     AccessLogConfig{Format: "combined", SampleRate: 1},
+			path:    "/users",
+			wantMsg: `"GET /users HTTP/1.1" 201 5 "https://example.com/" "test-agent"`,
+		},
+		{
+			name: "json attributes",
+			cfg:  AccessLogConfig{Format: "json", SampleRate: 1},
+			path: "/users",
+			wantAttr: map[string]any{
+				"msg":        "request",
+				"method":     "GET",
+				"path":       "/users",
+				"status":     float64(201),
+				"bytes":      float64(5),
+				"remote_ip":  "203.0.113.9",
+				"request_id": "req-1",
+			},
+		},
+		{
+			name:     "excluded exact path",
+			cfg:      AccessLogConfig{Format: "json", SampleRate: 1, Exclude: []string{"/healthz"}},
+			path:     "/healthz",
+			wantNone: true,
+		},
+		{
+			name:     "excluded prefix",
+			cfg:      AccessLogConfig{Format: "json", SampleRate: 1, Exclude: []string{"/public/*"}},
+			path:     "/public/css/app.css",
+			wantNone: true,
+		},
+		{
+			name:     "sampled out",
+			cfg:      AccessLogConfig{Format: "json", SampleRate: 0},
+			path:     "/users",
+			wantNone: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			var buf bytes.Buffer
+			c := &Celeritas{Logger: slog.New(&contextHandler{Handler: slog.NewJSONHandler(&buf, nil)})}
+			c.config.AccessLog = tt.cfg
+
+			handler := middleware.RequestID(middleware.RealIP(c.AccessLog(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte("hello"))
+				}))))
+
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.Header.Set("X-Real-IP", "203.0.113.9")
+			r.Header.Set("X-Request-Id", "req-1")
+			r.Header.Set("Referer", "https://example.com/")
+			r.Header.Set("User-Agent", "test-agent")
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if tt.wantNone {
+				if buf.Len() != 0 {
+					ts.Errorf("AccessLog() wrote %q, want nothing", buf.String())
+				}
+				return
+			}
+
+			var record map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				ts.Fatalf("AccessLog() wrote invalid record: %v\n%s", err, buf.String())
+			}
+			if tt.wantMsg != "" && !strings.Contains(record["msg"].(string), tt.wantMsg) {
+				ts.Errorf("AccessLog() msg = %q, want it to contain %q", record["msg"], tt.wantMsg)
+			}
+			for k, want := range tt.wantAttr {
+				if record[k] != want {
+					ts.Errorf("AccessLog() %s = %v, want %v", k, record[k], want)
+				}
+			}
+		})
+	}
+}
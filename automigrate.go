@@ -0,0 +1,160 @@
+package celeritas
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// AutoMigrateConfig holds the settings for running migrations at startup.
+type AutoMigrateConfig struct {
+	// Enabled runs the pending migrations while the application starts,
+	// before it serves requests. Startup fails if they fail, or if the
+	// schema was left dirty by an earlier failed migration.
+	Enabled bool `env:"AUTO_MIGRATE"`
+
+	// LockTimeout is how long to wait for another instance that is
+	// migrating the same database to finish before giving up.
+	LockTimeout time.Duration `env:"AUTO_MIGRATE_LOCK_TIMEOUT" default:"1m"`
+}
+
+// validate checks the auto-migrate settings.
+func (a AutoMigrateConfig) validate(dbType string) []string {
+	if !a.Enabled {
+		return nil
+	}
+	var problems []string
+	if dbType == "" {
+		problems = append(problems, "DATABASE_TYPE: required when AUTO_MIGRATE is set")
+	}
+	if a.LockTimeout <= 0 {
+		problems = append(problems, fmt.Sprintf("AUTO_MIGRATE_LOCK_TIMEOUT: must be greater than zero (got %s)", a.LockTimeout))
+	}
+	return problems
+}
+
+// migrationLockPoll is how often an instance retries the migration lock
+// while another instance holds it.
+const migrationLockPoll = 250 * time.Millisecond
+
+// autoMigrate runs the pending migrations when AUTO_MIGRATE is set. It holds
+// a database lock while it checks and migrates the schema, so when several
+// instances start at once one migrates and the others wait for it, then
+// find nothing left to do.
+func (c *Celeritas) autoMigrate(ctx context.Context) error {
+	if !c.config.Database.AutoMigrate.Enabled {
+		return nil
+	}
+
+	lockCtx, cancel := context.WithTimeout(ctx, c.config.Database.AutoMigrate.LockTimeout)
+	defer cancel()
+	unlock, err := c.lockMigrations(lockCtx)
+	if err != nil {
+		return fmt.Errorf("acquiring the migration lock: %w", err)
+	}
+	defer unlock()
+
+	dsn := c.MigrateURL()
+	before, err := c.MigrateStatus(dsn)
+	if err != nil {
+		return fmt.Errorf("reading migration status: %w", err)
+	}
+	var pending int
+	for _, s := range before {
+		if s.Dirty {
+			return fmt.Errorf("refusing to start: the schema is dirty at migration %d_%s; fix it, then run celeritas migrate force %d",
+				s.Version, s.Name, s.Version)
+		}
+		if !s.Applied {
+			pending++
+		}
+	}
+	if pending == 0 {
+		c.logger().Info("database schema is up to date", "migrations", len(before))
+		return nil
+	}
+
+	c.logger().Info("running pending migrations", "pending", pending)
+	migrateErr := c.MigrateUp(dsn)
+
+	// log what ran, including the migrations before one that failed
+	after, err := c.MigrateStatus(dsn)
+	if err != nil {
+		return errors.Join(migrateErr, err)
+	}
+	for i, s := range after {
+		if i < len(before) && !before[i].Applied && s.Applied && !s.Dirty {
+			c.logger().Info("applied migration", "version", s.Version, "name", s.Name)
+		}
+	}
+	if migrateErr != nil {
+		return fmt.Errorf("running migrations: %w", migrateErr)
+	}
+	return nil
+}
+
+// lockMigrations takes a lock that only one instance can hold, retrying
+// until ctx is done. PostgreSQL uses an advisory lock and MySQL a named lock;
+// both belong to a connection, which is kept out of the pool until unlock is
+// called. SQLite databases are not shared between hosts and take no lock.
+func (c *Celeritas) lockMigrations(ctx context.Context) (unlock func(), err error) {
+	var lock, release string
+	var id any
+	key := migrationLockKey(c.config.Database.Name)
+
+	switch c.DB.DataType {
+	case "postgres", "postgresql", "pgx":
+		lock, release = "select pg_try_advisory_lock($1)", "select pg_advisory_unlock($1)"
+		id = int64(key)
+	case "mysql", "mariadb":
+		lock, release = "select coalesce(get_lock(?, 0), 0)", "select release_lock(?)"
+		id = fmt.Sprintf("celeritas_migrate_%x", key)
+	default:
+		return func() {}, nil
+	}
+
+	conn, err := c.DB.Writer().Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for waited := false; ; waited = true {
+		var locked bool
+		if err := conn.QueryRowContext(ctx, lock, id).Scan(&locked); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if locked {
+			break
+		}
+		if !waited {
+			c.logger().Info("waiting for another instance to finish migrating")
+		}
+		select {
+		case <-ctx.Done():
+			conn.Close()
+			return nil, ctx.Err()
+		case <-time.After(migrationLockPoll):
+		}
+	}
+
+	return func() {
+		var released sql.NullBool
+		if err := conn.QueryRowContext(context.Background(), release, id).Scan(&released); err != nil {
+			c.logger().Warn("releasing the migration lock", "error", err)
+		}
+		conn.Close()
+	}, nil
+}
+
+// migrationLockKey derives the lock identifier from the database name, so
+// applications migrating different databases on one server do not wait on
+// each other. It differs from the lock golang-migrate takes while migrating.
+func migrationLockKey(dbName string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte("celeritas:migrate:" + dbName))
+	return h.Sum64()
+}
@@ -0,0 +1,107 @@
+package celeritas
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var autoMigrations = fstest.MapFS{
+	"1_create_a.up.sql":   {Data: []byte("create table a (id integer);")},
+	"1_create_a.down.sql": {Data: []byte("drop table a;")},
+	"2_create_b.up.sql":   {Data: []byte("create table b (id integer);")},
+	"2_create_b.down.sql": {Data: []byte("drop table b;")},
+}
+
+// newAutoMigrateApp starts an application with AUTO_MIGRATE set on the SQLite
+// database in root, returning it and what it logged.
+func newAutoMigrateApp(t *testing.T, root string, migrations fstest.MapFS) (*Celeritas, string, error) {
+	t.Helper()
+	c, logged, err := startSQLiteApp(t, root, func(cfg *Config) {
+		cfg.Database.AutoMigrate = AutoMigrateConfig{Enabled: true, LockTimeout: time.Second}
+	}, WithMigrations(migrations))
+	return c, logged.String(), err
+}
+
+func TestCeleritas_autoMigrate(t *testing.T) {
+	root := t.TempDir()
+
+	c, logged, err := newAutoMigrateApp(t, root, autoMigrations)
+	if err != nil {
+		t.Fatalf("NewApp() unexpected error: %v", err)
+	}
+	if _, err := c.DB.Pool.Exec("insert into b (id) values (1)"); err != nil {
+		t.Errorf("table from migration 2 is missing: %v", err)
+	}
+	for _, want := range []string{"version=1 name=create_a", "version=2 name=create_b"} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("NewApp() logged %q, want the applied migration %q", logged, want)
+		}
+	}
+
+	// a second instance finds nothing to do
+	_, logged, err = newAutoMigrateApp(t, root, autoMigrations)
+	if err != nil {
+		t.Fatalf("NewApp() second start unexpected error: %v", err)
+	}
+	if !strings.Contains(logged, "database schema is up to date") || strings.Contains(logged, "applied migration") {
+		t.Errorf("NewApp() second start logged %q, want the schema up to date", logged)
+	}
+}
+
+func TestCeleritas_autoMigrate_dirty(t *testing.T) {
+	root := t.TempDir()
+	broken := fstest.MapFS{
+		"1_create_a.up.sql": {Data: []byte("create table a (id integer);")},
+		"2_broken.up.sql":   {Data: []byte("this is not sql;")},
+	}
+
+	if _, _, err := newAutoMigrateApp(t, root, broken); err == nil || !strings.Contains(err.Error(), "running migrations") {
+		t.Fatalf("NewApp() error = %v, want the failed migration", err)
+	}
+
+	_, _, err := newAutoMigrateApp(t, root, broken)
+	if err == nil || !strings.Contains(err.Error(), "dirty at migration 2_broken") {
+		t.Fatalf("NewApp() error = %v, want a refusal to start on a dirty schema", err)
+	}
+
+	db, err := sql.Open("sqlite", filepath.Join(root, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var version int
+	db.QueryRow("select version from schema_migrations").Scan(&version)
+	if version != 2 {
+		t.Errorf("schema version = %d, want it left at the dirty migration", version)
+	}
+}
+
+func TestAutoMigrateConfig_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbType  string
+		cfg     AutoMigrateConfig
+		wantErr string
+	}{
+		{"disabled", "", AutoMigrateConfig{}, ""},
+		{"valid", "postgres", AutoMigrateConfig{Enabled: true, LockTimeout: time.Minute}, ""},
+		{"no database", "", AutoMigrateConfig{Enabled: true, LockTimeout: time.Minute}, "DATABASE_TYPE"},
+		{"no timeout", "mysql", AutoMigrateConfig{Enabled: true}, "AUTO_MIGRATE_LOCK_TIMEOUT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			problems := strings.Join(tt.cfg.validate(tt.dbType), "\n")
+			if tt.wantErr == "" && problems != "" {
+				ts.Errorf("validate() = %q, want no problems", problems)
+			}
+			if tt.wantErr != "" && !strings.Contains(problems, tt.wantErr) {
+				ts.Errorf("validate() = %q, want %q", problems, tt.wantErr)
+			}
+		})
+	}
+}
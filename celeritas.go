@@ -1,22 +1,29 @@
 package celeritas
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/CloudyKit/jet/v6"
 	"github.com/alexedwards/scs/v2"
-	"github.com/fatih/color"
 	"github.com/go-chi/chi/v5"
 	"github.com/gomodule/redigo/redis"
 	"github.com/joho/godotenv"
 
 	"github.com/polyglotdev/celeritasproject/cache"
+	"github.com/polyglotdev/celeritasproject/metrics"
 	"github.com/polyglotdev/celeritasproject/render"
 	"github.com/polyglotdev/celeritasproject/session"
 )
@@ -31,12 +38,12 @@ var myRedisCache *cache.RedisCache
 // for web applications including routing, logging, and configuration management.
 // It coordinates all the main components needed to run a web server.
 //
-// The zero value is not usable. Use New to create a new Celeritas instance.
+// The zero value is not usable. Use New or NewApp to create a new Celeritas instance.
 //
 // A Celeritas instance manages:
 //   - Application identification and versioning
 //   - Debug mode configuration
-//   - Structured logging (info and error levels)
+//   - Structured logging with log/slog
 //   - File system organization via RootPath
 //   - HTTP routing with Chi router
 //   - Server configuration (ports, rendering options)
@@ -46,33 +53,38 @@ var myRedisCache *cache.RedisCache
 // Celeritas is safe for use by a single goroutine at a time.
 type Celeritas struct {
 	AppName       string              // Application name used in logging and identification
+	Env           string              // Environment profile from APP_ENV (development, test, staging, production)
 	Debug         bool                // Debug mode flag for detailed logging and error handling
 	Version       string              // Application version for deployment tracking
-	ErrorLog      *log.Logger         // Structured error logging
-	InfoLog       *log.Logger         // Structured information logging
+	Logger        *slog.Logger        // Structured application logger
+	ErrorLog      *log.Logger         // Deprecated: Use Logger. Writes error records through Logger
+	InfoLog       *log.Logger         // Deprecated: Use Logger. Writes info records through Logger
 	RootPath      string              // Base directory for application files and folders
 	Routes        *chi.Mux            // HTTP router for handling web requests
-	config        config              // Internal server configuration settings
+	config        Config              // Settings loaded from .env and the environment
 	Render        *render.Render      // Rendering engine
 	Session       *scs.SessionManager // Session manager
 	DB            Database            // Database connection
 	JetViews      *jet.Set            // Jet template engine
 	EncryptionKey string              // EncryptionKey for PSQL
 	Cache         cache.Cacher        // Cache client
+	Metrics       *metrics.Registry   // Prometheus metrics served at METRICS_PATH when enabled
+	Migrations    fs.FS               // Migration files, when not read from the migrations folder
+	reqMetrics    *httpMetrics        // Request metrics shared by the routers
+	lifecycle     lifecycle           // Startup and shutdown hooks
+	health        healthChecks        // Checks run by the readiness endpoint
+	certs         *certReloader       // TLS certificate, when serving HTTPS
 }
 
-type config struct {
-	port        string
-	renderer    string
-	cookie      cookieConfig
-	sessionType string
-	database    databaseConfig
-	redis       redisConfig
-}
+// defaultShutdownTimeout is the grace period given to in-flight requests when
+// the configured shutdown timeout is not positive.
+const defaultShutdownTimeout = 30 * time.Second
 
-// New returns a new Celeritas application
+// New returns a new Celeritas application rooted at rootPath. It creates the
+// standard application folders and an empty .env file if they are missing,
+// then loads the configuration from the environment. Use NewApp to build an
+// application without touching the file system.
 func (c *Celeritas) New(rootPath string) error {
-	c.AppName = "celeritas"
 	pathConfig := initPaths{
 		rootPath: rootPath,
 		folderNames: []string{
@@ -84,6 +96,7 @@ func (c *Celeritas) New(rootPath string) error {
 			"tmp",
 			"logs",
 			"middleware",
+			"seeders",
 		},
 	}
 
@@ -95,91 +108,164 @@ func (c *Celeritas) New(rootPath string) error {
 		return err
 	}
 
-	// read .env file
-	if err := godotenv.Load(fmt.Sprintf("%s/.env", rootPath)); err != nil {
+	cfg, err := LoadConfig(rootPath)
+	if err != nil {
 		return err
 	}
 
-	if os.Getenv("CACHE") == "redis" || os.Getenv("SESSION_TYPE") == "redis" {
-		myRedisCache = c.createClientRedisCache()
-		c.Cache = myRedisCache
+	// read the layered .env files into the process environment so
+	// applications can read their own settings with os.Getenv
+	if err := loadDotEnv(rootPath); err != nil {
+		return err
+	}
+
+	return c.setup(options{rootPath: rootPath, config: &cfg})
+}
+
+// setup wires up the application's services from the resolved options. It is
+// shared by New and NewApp; anything supplied through options is used as is
+// and anything missing is created from the configuration.
+func (c *Celeritas) setup(o options) (err error) {
+	c.config = *o.config
+
+	// release the pools and files opened before a step failed
+	defer func() {
+		if err != nil {
+			err = c.abortStart(err)
+		}
+	}()
+
+	redisCache, _ := o.cache.(*cache.RedisCache)
+	needRedis := (o.cache == nil && c.config.Cache == "redis") ||
+		(o.sessionStore == nil && c.config.SessionType == "redis")
+	if needRedis && redisCache == nil {
+		redisCache = c.createClientRedisCache()
+		c.OnShutdown("redis", func(ctx context.Context) error {
+			return redisCache.Conn.Close()
+		})
+	}
+	myRedisCache = redisCache
+
+	c.Cache = o.cache
+	if c.Cache == nil && redisCache != nil {
+		c.Cache = redisCache
 	}
 
 	// start loggers
+	c.RootPath = o.rootPath
+	if o.logger != nil {
+		c.Logger = withRequestContext(o.logger)
+	} else {
+		logger, closer, err := newLogger(c.config.Log, c.RootPath)
+		if err != nil {
+			return fmt.Errorf("starting logger: %w", err)
+		}
+		c.Logger = logger
+		if closer != nil {
+			c.OnShutdown("logs", func(ctx context.Context) error {
+				return closer.Close()
+			})
+		}
+	}
 	infoLog, errorLog := c.StartLoggers()
+	if o.infoLog != nil {
+		infoLog = o.infoLog
+	}
+	if o.errorLog != nil {
+		errorLog = o.errorLog
+	}
 	c.InfoLog = infoLog
 	c.ErrorLog = errorLog
-	c.Debug, _ = strconv.ParseBool(os.Getenv("DEBUG"))
+	c.AppName = c.config.AppName
+	c.Env = c.config.Env
+	c.Debug = c.config.Debug
 	c.Version = Version
-	c.RootPath = rootPath
+	c.Metrics = metrics.NewRegistry()
 	c.Routes = c.routes().(*chi.Mux)
 
-	if os.Getenv("DATABASE_TYPE") != "" {
-		db, err := c.OpenDB(os.Getenv("DATABASE_TYPE"), c.BuildDSN())
+	if o.db != nil {
+		c.DB = Database{
+			DataType: c.config.Database.Type,
+			Pool:     o.db,
+		}
+	} else if c.config.Database.Type != "" {
+		db, err := c.connectDB(context.Background())
 		if err != nil {
-			c.ErrorLog.Println(err)
-			os.Exit(1)
+			return fmt.Errorf("connecting to %s database: %w", c.config.Database.Type, err)
 		}
 		c.DB = Database{
-			DataType: os.Getenv("DATABASE_TYPE"),
+			DataType: c.config.Database.Type,
 			Pool:     db,
 		}
+		c.OnShutdown("database", func(ctx context.Context) error {
+			return db.Close()
+		})
 	}
 
-	// read in all config settings
-	c.config = config{
-		port:     os.Getenv("PORT"),
-		renderer: os.Getenv("RENDERER"),
-		cookie: cookieConfig{
-			name:     os.Getenv("COOKIE_NAME"),
-			lifetime: os.Getenv("COOKIE_LIFETIME"),
-			persist:  os.Getenv("COOKIE_PERSIST"),
-			secure:   os.Getenv("COOKIE_SECURE"),
-			domain:   os.Getenv("COOKIE_DOMAIN"),
-		},
-		sessionType: os.Getenv("SESSION_TYPE"),
-		database: databaseConfig{
-			database: os.Getenv("DATABASE_TYPE"),
-			dsn:      c.BuildDSN(),
-		},
-		redis: redisConfig{
-			host:     os.Getenv("REDIS_HOST"),
-			password: os.Getenv("REDIS_PASSWORD"),
-			prefix:   os.Getenv("REDIS_PREFIX"),
-		},
+	if err := c.setupReplicas(o.replicas); err != nil {
+		return err
+	}
+
+	// migrate before anything, such as a database session store, uses the
+	// schema
+	c.Migrations = o.migrations
+	if err := c.autoMigrate(context.Background()); err != nil {
+		return err
 	}
 
 	sessionInfo := session.Session{
-		CookieLifetime: c.config.cookie.lifetime,
-		CookiePersist:  c.config.cookie.persist,
-		CookieName:     c.config.cookie.name,
-		CookieDomain:   c.config.cookie.domain,
-		SessionType:    c.config.sessionType,
+		CookieLifetime: strconv.Itoa(c.config.Cookie.Lifetime),
+		CookiePersist:  strconv.FormatBool(c.config.Cookie.Persist),
+		CookieSecure:   strconv.FormatBool(c.config.Cookie.Secure),
+		CookieName:     c.config.Cookie.Name,
+		CookieDomain:   c.config.Cookie.Domain,
+		SessionType:    c.config.SessionType,
 	}
 
-	switch c.config.sessionType {
-	case "redis":
-		sessionInfo.RedisPool = myRedisCache.Conn
-	case "mysql", "postgres", "mariadb", "postgresql":
-		sessionInfo.DBPool = c.DB.Pool
+	if o.sessionStore == nil {
+		switch c.config.SessionType {
+		case "redis":
+			sessionInfo.RedisPool = redisCache.Conn
+		case "mysql", "postgres", "mariadb", "postgresql", "sqlite":
+			sessionInfo.DBPool = c.DB.Pool
+		}
+	}
+
+	if c.config.Metrics.Enabled {
+		c.setupMetrics(redisCache)
 	}
 
 	c.Session = sessionInfo.InitSession()
-	c.EncryptionKey = os.Getenv("KEY")
+	if o.sessionStore != nil {
+		c.Session.Store = o.sessionStore
+	}
+	c.EncryptionKey = c.config.Key
 
-	if c.Debug {
+	var loader jet.Loader = jet.NewOSFileSystemLoader(filepath.Join(c.RootPath, "views"))
+	if o.views != nil {
+		loader = render.NewFSLoader(o.views)
+	}
+
+	if c.devMode() {
 		var views = jet.NewSet(
-			jet.NewOSFileSystemLoader(fmt.Sprintf("%s/views", rootPath)),
+			loader,
 			jet.InDevelopmentMode(),
 		)
 		c.JetViews = views
 	} else {
 		c.JetViews = jet.NewSet(
-			jet.NewOSFileSystemLoader(fmt.Sprintf("%s/views", rootPath)),
+			loader,
 		)
 	}
 
 	c.createRenderer()
+	c.Render.Views = o.views
+
+	var redisPool *redis.Pool
+	if redisCache != nil {
+		redisPool = redisCache.Conn
+	}
+	c.setupHealth(redisPool)
 
 	return nil
 }
@@ -195,6 +281,53 @@ func (c *Celeritas) Init(p initPaths) error {
 	return nil
 }
 
+// loadDotEnv copies the settings from the layered .env files into the process
+// environment without overriding variables that are already set. godotenv
+// keeps the first value it sees, so files are loaded most specific first.
+func loadDotEnv(rootPath string) error {
+	files, err := envFiles(rootPath)
+	if err != nil {
+		return err
+	}
+
+	var existing []string
+	for i := len(files) - 1; i >= 0; i-- {
+		if _, err := os.Stat(files[i]); err == nil {
+			existing = append(existing, files[i])
+		}
+	}
+	if len(existing) == 0 {
+		return nil
+	}
+	return godotenv.Load(existing...)
+}
+
+// IsProduction reports whether the application is running with the
+// production profile.
+func (c *Celeritas) IsProduction() bool {
+	return c.Env == EnvProduction
+}
+
+// IsDevelopment reports whether the application is running with the
+// development profile.
+func (c *Celeritas) IsDevelopment() bool {
+	return c.Env == EnvDevelopment
+}
+
+// devMode reports whether development conveniences such as template
+// reloading and relaxed CSRF cookies should be enabled. They are always on in
+// development, never on in production, and follow DEBUG in other profiles.
+func (c *Celeritas) devMode() bool {
+	switch c.Env {
+	case EnvDevelopment:
+		return true
+	case EnvProduction:
+		return false
+	default:
+		return c.Debug
+	}
+}
+
 // checkDotEnv creates a .env file in the specified root directory if one doesn't exist.
 // It takes a path string and returns an error if the file creation fails.
 // If the .env file already exists, it does nothing and returns nil.
@@ -209,75 +342,212 @@ func (c *Celeritas) checkDotEnv(p string) error {
 // It returns the configured server and an error if configuration fails.
 //
 // The server is configured with:
-//   - Port from application config
+//   - Bind address from SERVER_HOST and PORT
 //   - Application error logger
-//   - Chi router as handler
-//   - Idle timeout of 30 seconds
-//   - Read timeout of 30 seconds
-//   - Write timeout of 600 seconds
+//   - Handler, which serves the built-in endpoints and the Chi router
+//   - Timeouts and header limit from the SERVER_* settings
+//   - TLS, when TLS_CERT_FILE is set, with the certificate served through a
+//     reloader so it can be replaced on SIGHUP
 //
-// If the port is not configured (empty), createServer returns an error.
+// If the port is not configured (empty) and no Unix-domain socket is set, or
+// the certificate cannot be loaded, createServer returns an error.
 func (c *Celeritas) createServer() (*http.Server, error) {
-	if c.config.port == "" {
+	if c.config.Port == "" && c.config.Server.Socket == "" {
 		return nil, fmt.Errorf("port cannot be empty")
 	}
 
 	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%s", c.config.port),
-		ErrorLog:     c.ErrorLog,
-		Handler:      c.Routes,
-		IdleTimeout:  30 * time.Second,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 600 * time.Second,
+		Addr:              net.JoinHostPort(c.config.Server.Host, c.config.Port),
+		ErrorLog:          c.ErrorLog,
+		Handler:           c.Handler(),
+		IdleTimeout:       c.config.Server.IdleTimeout,
+		ReadTimeout:       c.config.Server.ReadTimeout,
+		ReadHeaderTimeout: c.config.Server.ReadHeaderTimeout,
+		WriteTimeout:      c.config.Server.WriteTimeout,
+		MaxHeaderBytes:    c.config.Server.MaxHeaderBytes,
+	}
+
+	if c.config.TLS.Enabled() {
+		reloader, err := newCertReloader(c.config.TLS.CertFile, c.config.TLS.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		srv.TLSConfig, err = c.config.TLS.tlsConfig(reloader)
+		if err != nil {
+			return nil, err
+		}
+		c.certs = reloader
 	}
 
-	c.InfoLog.Printf("Starting %s on port %s", c.AppName, c.config.port)
+	if c.config.Server.Socket != "" {
+		c.InfoLog.Printf("Starting %s on socket %s", c.AppName, c.config.Server.Socket)
+	} else {
+		c.InfoLog.Printf("Starting %s on port %s", c.AppName, c.config.Port)
+	}
 	return srv, nil
 }
 
-// ListenAndServe starts and runs the HTTP server until it encounters an error.
-// It configures the server with:
+// ListenAndServe starts the HTTP server and blocks until it stops.
+//
+// Before accepting connections it runs every OnStart hook. It then serves
+// until the process receives SIGINT or SIGTERM, or Stop is called. When TLS
+// is enabled it also serves the HTTP-to-HTTPS redirect listener if one is
+// configured, and reloads the certificate on SIGHUP. On shutdown it:
 //
-//   - Idle timeout of 30 seconds
-//   - Read timeout of 30 seconds
-//   - Write timeout of 600 seconds
+//  1. Stops accepting new connections and waits for in-flight requests to
+//     finish, for at most the configured SHUTDOWN_TIMEOUT (30s by default)
+//  2. Runs every OnShutdown hook in reverse registration order, which by
+//     default closes the database pool and the Redis pool
 //
-// The server listens on the port specified in the application config.
-// If the server encounters an error during startup or operation, it logs
-// the error and terminates the application.
+// If the server cannot start, because an OnStart hook fails or the listener
+// cannot be opened, the OnShutdown hooks still run so nothing opened by New
+// is leaked.
+//
+// Errors from draining the server and from the shutdown hooks are joined and
+// returned together. A clean shutdown returns nil.
 func (c *Celeritas) ListenAndServe() error {
 	srv, err := c.createServer()
 	if err != nil {
-		return err
+		return c.abortStart(err)
+	}
+
+	if err := c.runStartHooks(context.Background()); err != nil {
+		return c.abortStart(err)
+	}
+
+	sigCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	ln, err := c.listen()
+	if err != nil {
+		return c.abortStart(err)
+	}
+
+	servers := []*http.Server{srv}
+	serveErr := make(chan error, 2)
+	go func() {
+		if srv.TLSConfig != nil {
+			serveErr <- srv.ServeTLS(ln, "", "")
+			return
+		}
+		serveErr <- srv.Serve(ln)
+	}()
+
+	if redirect := c.createRedirectServer(); redirect != nil {
+		servers = append(servers, redirect)
+		go func() {
+			serveErr <- redirect.ListenAndServe()
+		}()
+	}
+
+	if c.certs != nil {
+		stopReload := c.reloadCertsOnSIGHUP()
+		defer stopReload()
+	}
+
+	select {
+	case err := <-serveErr:
+		// A server failed before a shutdown was requested, for example
+		// because the port is already in use.
+		c.lifecycle.shuttingDown.Store(true)
+		ctx, cancel := context.WithTimeout(context.Background(), c.shutdownTimeout())
+		defer cancel()
+		for _, s := range servers {
+			_ = s.Close()
+		}
+		return errors.Join(err, c.runShutdownHooks(ctx))
+	case <-sigCtx.Done():
+	case <-c.lifecycle.stopChan():
 	}
-	defer c.DB.Pool.Close()
 
-	return srv.ListenAndServe()
+	c.logger().Info("shutting down", "app", c.AppName)
+	c.lifecycle.shuttingDown.Store(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.shutdownTimeout())
+	defer cancel()
+
+	var errs []error
+	for _, s := range servers {
+		if err := s.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("http server shutdown: %w", err))
+		}
+	}
+	for range servers {
+		if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, err)
+		}
+	}
+	if err := c.runShutdownHooks(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
 }
 
-// StartLoggers initializes the application's logging system with two loggers:
-// an InfoLog for general information (in green) and an ErrorLog for error messages (in red).
-// Both loggers write to standard output with different prefixes, formats, and colors.
-// It returns two loggers for info and error logging respectively.
-func (c *Celeritas) StartLoggers() (*log.Logger, *log.Logger) {
-	var infoLog *log.Logger
-	var errorLog *log.Logger
+// reloadCertsOnSIGHUP reloads the TLS certificate each time the process
+// receives SIGHUP until the returned function is called. A certificate that
+// fails to load is logged and the previous one stays in use.
+func (c *Celeritas) reloadCertsOnSIGHUP() (stop func()) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-hup:
+				if err := c.certs.reload(); err != nil {
+					c.logger().Error("reloading TLS certificate", "error", err)
+					continue
+				}
+				c.logger().Info("reloaded TLS certificate", "file", c.certs.certFile)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(hup)
+		close(done)
+	}
+}
 
-	// Create colored prefixes using fatih/color
-	infoPrefix := color.New(color.FgGreen).Sprint("INFO\t")
-	errorPrefix := color.New(color.FgRed).Sprint("ERROR\t")
+// abortStart runs the shutdown hooks after New or ListenAndServe failed to
+// start, so the pools and files opened so far are released, and returns err
+// along with any errors from the hooks.
+func (c *Celeritas) abortStart(err error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.shutdownTimeout())
+	defer cancel()
+	return errors.Join(err, c.runShutdownHooks(ctx))
+}
 
-	infoLog = log.New(os.Stdout, infoPrefix, log.Ldate|log.Ltime)
-	errorLog = log.New(os.Stdout, errorPrefix, log.Ldate|log.Ltime|log.Lshortfile)
+// shutdownTimeout returns the grace period allowed for draining requests and
+// running shutdown hooks.
+func (c *Celeritas) shutdownTimeout() time.Duration {
+	if c.config.ShutdownTimeout <= 0 {
+		return defaultShutdownTimeout
+	}
+	return c.config.ShutdownTimeout
+}
+
+// StartLoggers returns an InfoLog and an ErrorLog that write info and error
+// records through the application's structured logger.
+//
+// Deprecated: Use the Logger field.
+func (c *Celeritas) StartLoggers() (*log.Logger, *log.Logger) {
+	handler := c.logger().Handler()
+	infoLog := slog.NewLogLogger(handler, slog.LevelInfo)
+	errorLog := slog.NewLogLogger(handler, slog.LevelError)
 
 	return infoLog, errorLog
 }
 
 func (c *Celeritas) createRenderer() {
 	myRenderer := render.Render{
-		Renderer: c.config.renderer,
+		Renderer: c.config.Renderer,
 		RootPath: c.RootPath,
-		Port:     c.config.port,
+		Port:     c.config.Port,
 		JetViews: c.JetViews,
 		Session:  c.Session,
 	}
@@ -288,7 +558,7 @@ func (c *Celeritas) createRenderer() {
 func (c *Celeritas) createClientRedisCache() *cache.RedisCache {
 	return &cache.RedisCache{
 		Conn:   c.createRedisPool(),
-		Prefix: c.config.redis.prefix,
+		Prefix: c.config.Redis.Prefix,
 	}
 }
 
@@ -299,7 +569,7 @@ func (c *Celeritas) createRedisPool() *redis.Pool {
 		MaxActive:   12000,
 		IdleTimeout: 240 * time.Second,
 		Dial: func() (redis.Conn, error) {
-			return redis.Dial("tcp", c.config.redis.host, redis.DialPassword(c.config.redis.password))
+			return redis.Dial("tcp", c.config.Redis.Host, redis.DialPassword(c.config.Redis.Password))
 		},
 		TestOnBorrow: func(conn redis.Conn, t time.Time) error {
 			reply, err := conn.Do("GET", "mykey")
@@ -318,28 +588,18 @@ func (c *Celeritas) createRedisPool() *redis.Pool {
 	}
 }
 
-// BuildDSN builds the datasource name for our database, and returns it as a string.
-func (c *Celeritas) BuildDSN() string {
-	var dsn string
-
-	switch os.Getenv("DATABASE_TYPE") {
-	case "postgres", "postgresql":
-		dsn = fmt.Sprintf(
-			"host=%s port=%s user=%s dbname=%s sslmode=%s timezone=UTC connect_timeout=5",
-			os.Getenv("DATABASE_HOST"),
-			os.Getenv("DATABASE_PORT"),
-			os.Getenv("DATABASE_USER"),
-			os.Getenv("DATABASE_NAME"),
-			os.Getenv("DATABASE_SSL_MODE"),
-		)
-
-		if os.Getenv("DATABASE_PASS") != "" {
-			dsn = fmt.Sprintf("%s password=%s", dsn, os.Getenv("DATABASE_PASS"))
-		}
-
-	default:
+// Config returns the settings the application was started with.
+func (c *Celeritas) Config() Config {
+	return c.config
+}
 
-	}
+// MigrateURL returns the URL the migrate methods connect to the
+// application's database with.
+func (c *Celeritas) MigrateURL() string {
+	return c.config.Database.withRoot(c.RootPath).MigrateURL()
+}
 
-	return dsn
+// BuildDSN builds the datasource name for our database, and returns it as a string.
+func (c *Celeritas) BuildDSN() string {
+	return c.config.Database.withRoot(c.RootPath).DSN()
 }
@@ -104,13 +104,13 @@ func TestCeleritas_New(t *testing.T) {
 					{"Debug", c.Debug, true, "Debug not set correctly"},
 					{"Version", c.Version, Version, "Version not set correctly"},
 					{"RootPath", c.RootPath, tt.rootPath, "RootPath not set correctly"},
-					{"config.port", c.config.port, "8080", "Port not set correctly"},
-					{"config.renderer", c.config.renderer, "go", "Renderer not set correctly"},
+					{"config.Port", c.config.Port, "8080", "Port not set correctly"},
+					{"config.Renderer", c.config.Renderer, "go", "Renderer not set correctly"},
 					// Add new test for Renderer initialization
 					{"Renderer", c.Render, &render.Render{
-						Renderer: c.config.renderer,
+						Renderer: c.config.Renderer,
 						RootPath: c.RootPath,
-						Port:     c.config.port,
+						Port:     c.config.Port,
 					}, "Renderer not initialized correctly"},
 				}
 
@@ -173,7 +173,7 @@ func TestCeleritas_ListenAndServe(t *testing.T) {
 			name: "valid port configuration",
 			setup: func(c *Celeritas) {
 				c.AppName = "test_app"
-				c.config.port = "0"
+				c.config.Port = "0"
 				c.InfoLog = log.New(&logBuffer, "INFO\t", log.Ldate|log.Ltime)
 				c.ErrorLog = log.New(io.Discard, "", 0)
 				c.Routes = chi.NewRouter()
@@ -187,7 +187,7 @@ func TestCeleritas_ListenAndServe(t *testing.T) {
 			name: "missing port configuration",
 			setup: func(c *Celeritas) {
 				c.AppName = "test_app"
-				c.config.port = ""
+				c.config.Port = ""
 				c.InfoLog = log.New(&logBuffer, "INFO\t", log.Ldate|log.Ltime)
 				c.ErrorLog = log.New(io.Discard, "", 0)
 				c.Routes = chi.NewRouter()
@@ -240,10 +240,36 @@ func TestCeleritas_ListenAndServe(t *testing.T) {
 			}
 
 			// Verify port configuration
-			if c.config.port != testCase.wantPort {
+			if c.config.Port != testCase.wantPort {
 				ts.Errorf("Port configuration incorrect\nwant: %q\ngot: %q",
 					testCase.wantPort,
-					c.config.port)
+					c.config.Port)
+			}
+		})
+	}
+}
+
+func TestCeleritas_devMode(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   string
+		debug bool
+		want  bool
+	}{
+		{name: "development", env: EnvDevelopment, want: true},
+		{name: "production ignores debug", env: EnvProduction, debug: true, want: false},
+		{name: "staging follows debug", env: EnvStaging, debug: true, want: true},
+		{name: "test without debug", env: EnvTest, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			c := &Celeritas{Env: tt.env, Debug: tt.debug}
+			if got := c.devMode(); got != tt.want {
+				ts.Errorf("devMode() = %v, want %v", got, tt.want)
+			}
+			if got := c.IsProduction(); got != (tt.env == EnvProduction) {
+				ts.Errorf("IsProduction() = %v for %s", got, tt.env)
 			}
 		})
 	}
@@ -22,13 +22,15 @@ import (
 //
 // Template files for models and middleware are copied to their respective
 // directories to maintain separation of concerns and follow standard Go project
-// layout conventions.
+// layout conventions. The models are only generated for the database types
+// the upper/db models can connect to, and a SQLite application gets the
+// tables and middleware without them.
 //
 // The function provides immediate feedback through colored console output to guide
 // developers through necessary post-setup steps, reducing the likelihood of
 // incomplete authentication implementation.
 func doAuth() error {
-	dbType := cel.DB.DataType
+	dbType := migrationDialect(cel.DB.DataType)
 	filename := fmt.Sprintf("%d_create_auth_tables", time.Now().UnixMicro())
 	upFile := cel.RootPath + "/migrations/" + filename + ".up.sql"
 	downFile := cel.RootPath + "/migrations/" + filename + ".down.sql"
@@ -38,27 +40,30 @@ func doAuth() error {
 		exitGracefully(err)
 	}
 
-	err = copyDataToFile([]byte(`
-drop table if exists remember_tokens cascade;
-drop table if exists tokens cascade;
-drop table if exists users cascade;
-`), downFile)
-	if err != nil {
-		exitGracefully(err)
+	// SQLite has no DROP TABLE ... CASCADE; dropping the dependent tables
+	// first makes it unnecessary there.
+	cascade := " cascade"
+	if dbType == "sqlite" {
+		cascade = ""
 	}
-	err = doMigrate("up", "")
+	err = copyDataToFile([]byte(fmt.Sprintf(`
+drop table if exists remember_tokens%[1]s;
+drop table if exists tokens%[1]s;
+drop table if exists users%[1]s;
+`, cascade)), downFile)
 	if err != nil {
 		exitGracefully(err)
 	}
-
-	err = copyFileFromTemplate("templates/data/user.go.txt", cel.RootPath+"/data/user.go")
+	err = doMigrate("up", "")
 	if err != nil {
 		exitGracefully(err)
 	}
 
-	err = copyFileFromTemplate("templates/data/token.go.txt", cel.RootPath+"/data/token.go")
-	if err != nil {
-		exitGracefully(err)
+	models := supportsModels(cel.DB.DataType)
+	if models {
+		if err := makeAuthModels(); err != nil {
+			exitGracefully(err)
+		}
 	}
 
 	err = copyFileFromTemplate(
@@ -79,8 +84,29 @@ drop table if exists users cascade;
 
 	color.Green("dbType: %s", dbType)
 	color.Green("auth middleware created successfully")
+	if !models {
+		color.Yellow("The user and token models were not generated: they use upper/db, which celeritas supports only with postgres, mysql and mariadb, not DATABASE_TYPE %s", cel.DB.DataType)
+		color.Yellow("Don't forget to add auth middleware to routes.go")
+		return nil
+	}
 	color.Yellow("Don't forget to:")
-	color.Yellow("1. Add User and Token models to data/models")
+	color.Yellow("1. Create the models with data.New(app.DB) and keep them on the application")
 	color.Yellow("2. Add auth middleware to routes.go")
 	return nil
 }
+
+// makeAuthModels generates the user and token models and their factories in
+// the data folder, and registers the models in data/models.go.
+func makeAuthModels() error {
+	for _, name := range []string{"user", "token", "factories"} {
+		err := copyFileFromTemplate("templates/data/"+name+".go.txt", cel.RootPath+"/data/"+name+".go")
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := registerModel("Users", "User"); err != nil {
+		return err
+	}
+	return registerModel("Tokens", "Token")
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMakeAuthModels_compiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the generated package with the go command")
+	}
+	repo, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dbType := range []string{"postgres", "postgresql", "pgx", "mysql", "mariadb"} {
+		t.Run(dbType, func(ts *testing.T) {
+			cel.RootPath = ts.TempDir()
+			cel.DB.DataType = dbType
+			if err := os.Mkdir(filepath.Join(cel.RootPath, "data"), 0755); err != nil {
+				ts.Fatal(err)
+			}
+			goMod := fmt.Sprintf("module example.com/app\n\ngo 1.24\n\nrequire github.com/polyglotdev/celeritasproject v0.0.0\n\nreplace github.com/polyglotdev/celeritasproject => %s\n", repo)
+			if err := os.WriteFile(filepath.Join(cel.RootPath, "go.mod"), []byte(goMod), 0644); err != nil {
+				ts.Fatal(err)
+			}
+
+			if err := makeAuthModels(); err != nil {
+				ts.Fatalf("makeAuthModels() unexpected error: %v", err)
+			}
+
+			goCmd := func(args ...string) ([]byte, error) {
+				cmd := exec.Command("go", args...)
+				cmd.Dir = cel.RootPath
+				cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+				return cmd.CombinedOutput()
+			}
+			if out, err := goCmd("mod", "tidy"); err != nil {
+				if strings.Contains(string(out), "github.com/upper/db") {
+					ts.Skipf("upper/db cannot be downloaded: %s", out)
+				}
+				ts.Fatalf("go mod tidy: %v\n%s", err, out)
+			}
+			if out, err := goCmd("vet", "./data"); err != nil {
+				ts.Errorf("generated data package does not compile for %s: %v\n%s", dbType, err, out)
+			}
+		})
+	}
+}
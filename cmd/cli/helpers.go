@@ -1,67 +1,83 @@
 package main
 
 import (
-	"fmt"
 	"os"
+	"strings"
 
 	"github.com/fatih/color"
-	"github.com/joho/godotenv"
+	celeritas "github.com/polyglotdev/celeritasproject"
 )
 
 // setup initializes the application's core configuration by loading environment
 // variables and establishing the root path. This approach was chosen over
 // hard-coded configuration to support flexible deployment environments and
 // follow twelve-factor app principles for configuration management.
+//
+// Configuration is loaded through celeritas.LoadConfig so the CLI and the
+// application agree on defaults and every malformed setting is reported at once.
 func setup() {
-	err := godotenv.Load()
+	path, err := os.Getwd()
 	if err != nil {
 		exitGracefully(err)
 	}
 
-	path, err := os.Getwd()
+	cfg, err = celeritas.LoadConfig(path)
 	if err != nil {
 		exitGracefully(err)
 	}
 
 	cel.RootPath = path
-	cel.DB.DataType = os.Getenv("DATABASE_TYPE")
+	cel.DB.DataType = cfg.Database.Type
 }
 
-// getDSN constructs database connection strings with support for multiple database
-// types. The function handles PostgreSQL's unique connection string format separately
-// from other databases to accommodate its specific security and connection requirements.
-// This separation enables clean handling of optional password authentication while
-// maintaining consistent connection patterns across different database backends.
-func getDSN() string {
-	dbType := cel.DB.DataType
+// migrationDialects are the dialects with migration templates.
+var migrationDialects = []string{"postgres", "mysql", "sqlite"}
+
+// migrationDialect maps a DATABASE_TYPE to the dialect suffix used by the
+// migration templates and generated file names. Aliases share one set of
+// templates: mariadb uses the mysql ones, and postgresql and pgx use the
+// postgres ones.
+func migrationDialect(dbType string) string {
+	switch dbType {
+	case "mariadb":
+		return "mysql"
+	case "postgresql", "pgx":
+		return "postgres"
+	}
+	return dbType
+}
 
-	if dbType == "pgx" {
-		dbType = "postgres"
+// supportsModels reports whether the generated upper/db models can connect to
+// dbType; the New function in templates/data/models.go.txt handles these.
+func supportsModels(dbType string) bool {
+	switch migrationDialect(dbType) {
+	case "postgres", "mysql":
+		return true
 	}
+	return false
+}
 
-	if dbType == "postgres" {
-		var dsn string
-		if os.Getenv("DATABASE_PASS") != "" {
-			dsn = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-				os.Getenv("DATABASE_USER"),
-				os.Getenv("DATABASE_PASS"),
-				os.Getenv("DATABASE_HOST"),
-				os.Getenv("DATABASE_PORT"),
-				os.Getenv("DATABASE_NAME"),
-				os.Getenv("DATABASE_SSL_MODE"),
-			)
-		} else {
-			dsn = fmt.Sprintf("postgres://%s@%s:%s/%s?sslmode=%s",
-				os.Getenv("DATABASE_USER"),
-				os.Getenv("DATABASE_HOST"),
-				os.Getenv("DATABASE_PORT"),
-				os.Getenv("DATABASE_NAME"),
-				os.Getenv("DATABASE_SSL_MODE"),
-			)
+// parseFlags removes the --name flags from args, recording each in flags, and
+// returns the remaining positional arguments. Flags are boolean switches; a
+// bare -- ends flag parsing so later arguments are taken literally.
+func parseFlags(args []string) []string {
+	var positional []string
+	for i, arg := range args {
+		if arg == "--" {
+			return append(positional, args[i+1:]...)
+		}
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			flags[name] = true
+			continue
 		}
-		return dsn
+		positional = append(positional, arg)
 	}
-	return "mysql://" + cel.BuildDSN()
+	return positional
+}
+
+// hasFlag reports whether --name was given on the command line.
+func hasFlag(name string) bool {
+	return flags[name]
 }
 
 // contains provides a type-safe way to check for string membership in a slice.
@@ -171,11 +187,19 @@ func showHelp() {
 	migrate up            - run all up migrations
 	migrate down          - reverses most recent migration
 	migrate reset         - drop all tables and re-run all migrations
+	migrate status        - list migrations as applied, pending or dirty
+	migrate version       - print the current schema version
+	migrate goto <v>      - migrate up or down to version v
+	migrate steps <n>     - run n migrations, or roll back -n when negative
+	migrate force <v>     - set the version without migrating, clearing dirty
+	migrate up --dry-run  - print the SQL up, down or steps would run; fails if any is pending
 	make auth             - create and runs auth migrations, models, and middleware
 	make session          - create a table in the database as a session store
-	make migration <name> - create a new migration files for up and down migrations
+	make migration <name> - create a new migration files for up and down migrations (--sql for dialect-neutral files, --go for a Go migration)
 	make model <name>     - create a new model file
 	make handler <name>   - create a new handler file
 	make key              - generate a new encryption key
+	make seeder <name>    - create a new Go seeder (--sql for a SQL seeder)
+	db seed [name]        - run the seeders that have not run yet (--force in production)
 	`)
 }
@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestMigrationDialect(t *testing.T) {
+	tests := []struct {
+		dbType string
+		want   string
+	}{
+		{"postgres", "postgres"},
+		{"postgresql", "postgres"},
+		{"pgx", "postgres"},
+		{"mysql", "mysql"},
+		{"mariadb", "mysql"},
+		{"sqlite", "sqlite"},
+		{"oracle", "oracle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dbType, func(ts *testing.T) {
+			if got := migrationDialect(tt.dbType); got != tt.want {
+				ts.Errorf("migrationDialect(%q) = %q, want %q", tt.dbType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSupportsModels(t *testing.T) {
+	tests := []struct {
+		dbType string
+		want   bool
+	}{
+		{"postgres", true},
+		{"postgresql", true},
+		{"pgx", true},
+		{"mysql", true},
+		{"mariadb", true},
+		{"sqlite", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dbType, func(ts *testing.T) {
+			if got := supportsModels(tt.dbType); got != tt.want {
+				ts.Errorf("supportsModels(%q) = %v, want %v", tt.dbType, got, tt.want)
+			}
+		})
+	}
+}
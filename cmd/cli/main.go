@@ -11,7 +11,11 @@ import (
 
 const version = "1.0.0"
 
-var cel celeritas.Celeritas
+var (
+	cel   celeritas.Celeritas
+	cfg   celeritas.Config
+	flags = map[string]bool{}
+)
 
 func main() {
 	var message string
@@ -22,7 +26,7 @@ func main() {
 
 	setup()
 
-	validCommands := []string{"help", "version", "make", "migrate", "auth", "model"}
+	validCommands := []string{"help", "version", "make", "migrate", "db", "auth", "model"}
 	if !contains(validCommands, arg1) {
 		suggestion := findClosestMatch(arg1, validCommands)
 		if suggestion != "" {
@@ -40,7 +44,7 @@ func main() {
 
 	case "make":
 		if arg2 == "" {
-			exitGracefully(errors.New("make requires a subcommand: (migration|model|handler|middleware)"))
+			exitGracefully(errors.New("make requires a subcommand: (migration|model|handler|middleware|seeder)"))
 		}
 		err = doMake(arg2, arg3)
 		if err != nil {
@@ -55,7 +59,18 @@ func main() {
 		if err != nil {
 			exitGracefully(err)
 		}
-		message = "Migration " + arg2 + " completed"
+		if arg2 != "status" && arg2 != "version" && !hasFlag("dry-run") {
+			message = "Migration " + arg2 + " completed"
+		}
+
+	case "db":
+		if arg2 == "" {
+			exitGracefully(errors.New("db requires a subcommand: (seed)"))
+		}
+		message, err = doDB(arg2, arg3)
+		if err != nil {
+			exitGracefully(err)
+		}
 
 	default:
 		showHelp()
@@ -63,18 +78,22 @@ func main() {
 	exitGracefully(nil, message)
 }
 
+// validateInput returns the first three positional arguments. Flags such as
+// --force may appear anywhere on the command line; they are collected into
+// flags and do not count as positional arguments.
 func validateInput() (string, string, string, error) {
 	var arg1, arg2, arg3 string
 
-	if len(os.Args) > 1 {
-		arg1 = os.Args[1]
+	args := parseFlags(os.Args[1:])
+	if len(args) > 0 {
+		arg1 = args[0]
 
-		if len(os.Args) >= 3 {
-			arg2 = os.Args[2]
+		if len(args) >= 2 {
+			arg2 = args[1]
 		}
 
-		if len(os.Args) >= 4 {
-			arg3 = os.Args[3]
+		if len(args) >= 3 {
+			arg3 = args[2]
 		}
 	} else {
 		showHelp()
@@ -3,7 +3,10 @@ package main
 import (
 	"errors"
 	"fmt"
+	"go/format"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -41,6 +44,7 @@ import (
 //   - Uses microsecond precision timestamps to ensure unique ordering
 //   - Supports multiple database types through templating
 //   - Creates paired up/down migrations for reversibility
+//   - With --go, creates a Go migration registered under the same versioning
 //
 // Handler:
 //   - Implements smart parameter naming to avoid conflicts with http.ResponseWriter (w)
@@ -64,13 +68,13 @@ import (
 // to implement custom error handling strategies, except for fatal errors that should
 // terminate execution.
 func doMake(arg2, arg3 string) error {
-	validCommands := []string{"key", "migration", "model", "handler", "middleware"}
+	validCommands := []string{"key", "migration", "model", "handler", "middleware", "seeder"}
 	if !contains(validCommands, arg2) {
 		suggestion := findClosestMatch(arg2, validCommands)
 		if suggestion != "" {
-			return fmt.Errorf("invalid 'make' subcommand: %s\nDid you mean '%s'?\nValid subcommands are: key, migration, model, handler, middleware", arg2, suggestion)
+			return fmt.Errorf("invalid 'make' subcommand: %s\nDid you mean '%s'?\nValid subcommands are: key, migration, model, handler, middleware, seeder", arg2, suggestion)
 		}
-		return fmt.Errorf("invalid 'make' subcommand: %s\nValid subcommands are: key, migration, model, handler, middleware", arg2)
+		return fmt.Errorf("invalid 'make' subcommand: %s\nValid subcommands are: key, migration, model, handler, middleware, seeder", arg2)
 	}
 
 	switch arg2 {
@@ -129,15 +133,54 @@ func doMake(arg2, arg3 string) error {
 		color.Green("Key has been added to .env file")
 
 	case "migration":
-		dbType := cel.DB.DataType
 		if arg3 == "" {
 			return errors.New("you must specify a migration name")
 		}
 
-		fileName := fmt.Sprintf("%d_%s", time.Now().UnixMicro(), strcase.ToSnake(arg3))
+		version := time.Now().UnixMicro()
+		migrationName := strcase.ToSnake(arg3)
 
-		upFile := cel.RootPath + "/migrations/" + fileName + "." + dbType + ".up.sql"
-		downFile := cel.RootPath + "/migrations/" + fileName + "." + dbType + ".down.sql"
+		// With --go the migration is Go code in the migrations package,
+		// registered under the same version numbering as the SQL files.
+		if hasFlag("go") {
+			existing, _ := filepath.Glob(cel.RootPath + "/migrations/*_" + migrationName + ".go")
+			if len(existing) > 0 {
+				exitGracefully(fmt.Errorf("a Go migration named %s already exists: %s", migrationName, existing[0]))
+			}
+
+			data, err := templateFS.ReadFile("templates/migrations/migration.go.txt")
+			if err != nil {
+				exitGracefully(err)
+			}
+
+			migration := strings.ReplaceAll(string(data), "$VERSION$", strconv.FormatInt(version, 10))
+			migration = strings.ReplaceAll(migration, "$MIGRATIONNAME$", migrationName)
+			migration = strings.ReplaceAll(migration, "$TYPENAME$", strcase.ToCamel(arg3))
+
+			err = copyDataToFile([]byte(migration), fmt.Sprintf("%s/migrations/%d_%s.go", cel.RootPath, version, migrationName))
+			if err != nil {
+				exitGracefully(err)
+			}
+			break
+		}
+
+		// With --sql the files carry no dialect in their name and start from
+		// a dialect-neutral template; otherwise the configured database type
+		// picks the template.
+		dbType := "sql"
+		suffix := ""
+		if !hasFlag("sql") {
+			dbType = migrationDialect(cel.DB.DataType)
+			if !contains(migrationDialects, dbType) {
+				return fmt.Errorf("no migration templates for DATABASE_TYPE %q; set it to one of postgres, mysql, mariadb or sqlite, or use --sql", cel.DB.DataType)
+			}
+			suffix = "." + dbType
+		}
+
+		fileName := fmt.Sprintf("%d_%s", version, migrationName)
+
+		upFile := cel.RootPath + "/migrations/" + fileName + suffix + ".up.sql"
+		downFile := cel.RootPath + "/migrations/" + fileName + suffix + ".down.sql"
 
 		err := copyFileFromTemplate("templates/migrations/migration."+dbType+".up.sql", upFile)
 		if err != nil {
@@ -226,6 +269,52 @@ func doMake(arg2, arg3 string) error {
 			exitGracefully(err)
 		}
 
+		err = registerModel(pl.Plural(modelName), modelName)
+		if err != nil {
+			exitGracefully(err)
+		}
+
+	case "seeder":
+		if arg3 == "" {
+			exitGracefully(errors.New("you must specify a seeder name"))
+		}
+
+		dir := cel.RootPath + "/seeders"
+		err := os.MkdirAll(dir, 0755)
+		if err != nil {
+			exitGracefully(err)
+		}
+
+		// Go seeders share a package, so two with the same name would not
+		// compile; SQL seeders are held to the same rule for consistency.
+		seederName := strcase.ToSnake(arg3)
+		existing, _ := filepath.Glob(dir + "/*_" + seederName + ".*")
+		if len(existing) > 0 {
+			exitGracefully(fmt.Errorf("a seeder named %s already exists: %s", seederName, existing[0]))
+		}
+
+		fileName := fmt.Sprintf("%d_%s", time.Now().UnixMicro(), seederName)
+		if hasFlag("sql") {
+			err = copyFileFromTemplate("templates/seeders/seeder.sql.txt", dir+"/"+fileName+".sql")
+			if err != nil {
+				exitGracefully(err)
+			}
+			break
+		}
+
+		data, err := templateFS.ReadFile("templates/seeders/seeder.go.txt")
+		if err != nil {
+			exitGracefully(err)
+		}
+
+		seeder := strings.ReplaceAll(string(data), "$SEEDERNAME$", fileName)
+		seeder = strings.ReplaceAll(seeder, "$TYPENAME$", strcase.ToCamel(arg3)+"Seeder")
+
+		err = copyDataToFile([]byte(seeder), dir+"/"+fileName+".go")
+		if err != nil {
+			exitGracefully(err)
+		}
+
 	case "auth":
 		err := doAuth()
 		if err != nil {
@@ -240,3 +329,59 @@ func doMake(arg2, arg3 string) error {
 	}
 	return nil
 }
+
+// Markers in data/models.go that registerModel inserts new models above.
+const (
+	modelsFieldMarker = "// celeritas:models"
+	modelsNewMarker   = "// celeritas:new"
+)
+
+// registerModel adds a model to the Models registry in data/models.go as a
+// field named field of type typeName, creating the registry from its template
+// on first use. Inserting above marker comments keeps any code the developer
+// has added to the file intact; if the markers have been removed the model
+// must be registered by hand.
+func registerModel(field, typeName string) error {
+	fileName := cel.RootPath + "/data/models.go"
+	if !fileExists(fileName) {
+		if err := copyFileFromTemplate("templates/data/models.go.txt", fileName); err != nil {
+			return err
+		}
+	}
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	models := string(data)
+
+	if strings.Contains(models, "\t"+field+" ") {
+		return nil // already registered
+	}
+	if !strings.Contains(models, modelsFieldMarker) || !strings.Contains(models, modelsNewMarker) {
+		return fmt.Errorf("%s has no %q and %q markers; add %s %s to Models by hand",
+			fileName, modelsFieldMarker, modelsNewMarker, field, typeName)
+	}
+
+	models = insertAboveMarker(models, modelsFieldMarker, fmt.Sprintf("%s %s", field, typeName))
+	models = insertAboveMarker(models, modelsNewMarker, fmt.Sprintf("%s: %s{},", field, typeName))
+
+	out := []byte(models)
+	if formatted, err := format.Source(out); err == nil {
+		out = formatted
+	}
+	if err := os.WriteFile(fileName, out, 0644); err != nil {
+		return err
+	}
+	color.Green("registered %s in %s", typeName, fileName)
+	return nil
+}
+
+// insertAboveMarker inserts line above the line holding marker, with the
+// same indentation.
+func insertAboveMarker(src, marker, line string) string {
+	i := strings.Index(src, marker)
+	start := strings.LastIndex(src[:i], "\n") + 1
+	indent := src[start:i]
+	return src[:start] + indent + line + "\n" + src[start:]
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegisterModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string // data/models.go before, empty to create it from the template
+		register []string
+		want     []string
+		wantErr  string
+	}{
+		{
+			name:     "first model creates the registry",
+			register: []string{"Widgets"},
+			want:     []string{"\tWidgets Widget\n\t// celeritas:models", "\t\tWidgets: Widget{},\n\t\t// celeritas:new"},
+		},
+		{
+			name:     "second model goes below the first",
+			register: []string{"Widgets", "Orders"},
+			want:     []string{"\tWidgets Widget\n\tOrders  Widget\n", "\t\tWidgets: Widget{},\n\t\tOrders:  Widget{},\n"},
+		},
+		{
+			name:     "registering twice adds the model once",
+			register: []string{"Widgets", "Widgets"},
+			want:     []string{"\tWidgets Widget\n\t// celeritas:models"},
+		},
+		{
+			name:     "missing markers",
+			existing: "package data\n\ntype Models struct {\n\tUsers User\n}\n",
+			register: []string{"Widgets"},
+			wantErr:  "add Widgets Widget to Models by hand",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			cel.RootPath = ts.TempDir()
+			fileName := filepath.Join(cel.RootPath, "data", "models.go")
+			if err := os.Mkdir(filepath.Dir(fileName), 0755); err != nil {
+				ts.Fatal(err)
+			}
+			if tt.existing != "" {
+				if err := os.WriteFile(fileName, []byte(tt.existing), 0644); err != nil {
+					ts.Fatal(err)
+				}
+			}
+
+			var err error
+			for _, field := range tt.register {
+				if err = registerModel(field, "Widget"); err != nil {
+					break
+				}
+			}
+
+			data, readErr := os.ReadFile(fileName)
+			if readErr != nil {
+				ts.Fatal(readErr)
+			}
+			got := string(data)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					ts.Errorf("registerModel() error = %v, want %q", err, tt.wantErr)
+				}
+				if got != tt.existing {
+					ts.Errorf("registerModel() changed the file to:\n%s", got)
+				}
+				return
+			}
+			if err != nil {
+				ts.Fatalf("registerModel() unexpected error: %v", err)
+			}
+			for _, want := range tt.want {
+				if strings.Count(got, want) != 1 {
+					ts.Errorf("models.go has %q %d times, want once, in:\n%s", want, strings.Count(got, want), got)
+				}
+			}
+		})
+	}
+}
+
+func TestInsertAboveMarker(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"indented", "a\n\t\t// here\nb\n", "a\n\t\tnew\n\t\t// here\nb\n"},
+		{"first line", "// here\n", "new\n// here\n"},
+		{"first of two markers", "\t// here\n\t// here\n", "\tnew\n\t// here\n\t// here\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			if got := insertAboveMarker(tt.src, "// here", "new"); got != tt.want {
+				ts.Errorf("insertAboveMarker() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoMake_migration(t *testing.T) {
+	tests := []struct {
+		name     string
+		dbType   string
+		flag     string
+		template string // template the up and down files are copied from, without the direction
+		suffix   string // after the version and name in the generated file names
+		wantErr  string
+	}{
+		{name: "postgres", dbType: "postgres", template: "migration.postgres", suffix: ".postgres"},
+		{name: "postgresql alias", dbType: "postgresql", template: "migration.postgres", suffix: ".postgres"},
+		{name: "pgx alias", dbType: "pgx", template: "migration.postgres", suffix: ".postgres"},
+		{name: "mariadb alias", dbType: "mariadb", template: "migration.mysql", suffix: ".mysql"},
+		{name: "sqlite", dbType: "sqlite", template: "migration.sqlite", suffix: ".sqlite"},
+		{name: "sql flag", dbType: "postgres", flag: "sql", template: "migration.sql"},
+		{name: "sql flag without a database", flag: "sql", template: "migration.sql"},
+		{name: "unsupported database", dbType: "oracle", wantErr: `no migration templates for DATABASE_TYPE "oracle"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			cel.RootPath = ts.TempDir()
+			cel.DB.DataType = tt.dbType
+			flags = map[string]bool{}
+			if tt.flag != "" {
+				flags[tt.flag] = true
+			}
+			ts.Cleanup(func() { flags = map[string]bool{} })
+			dir := filepath.Join(cel.RootPath, "migrations")
+			if err := os.Mkdir(dir, 0755); err != nil {
+				ts.Fatal(err)
+			}
+
+			err := doMake("migration", "CreateWidgets")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					ts.Errorf("doMake() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				ts.Fatalf("doMake() unexpected error: %v", err)
+			}
+
+			for _, direction := range []string{"up", "down"} {
+				files, _ := filepath.Glob(filepath.Join(dir, "*_create_widgets"+tt.suffix+"."+direction+".sql"))
+				if len(files) != 1 {
+					entries, _ := os.ReadDir(dir)
+					ts.Fatalf("doMake() did not write a %s file named *_create_widgets%s.%s.sql, wrote %v", direction, tt.suffix, direction, entries)
+				}
+				got, err := os.ReadFile(files[0])
+				if err != nil {
+					ts.Fatal(err)
+				}
+				want, err := templateFS.ReadFile("templates/migrations/" + tt.template + "." + direction + ".sql")
+				if err != nil {
+					ts.Fatal(err)
+				}
+				if string(got) != string(want) {
+					ts.Errorf("%s = %q, want the %s.%s.sql template", filepath.Base(files[0]), got, tt.template, direction)
+				}
+			}
+		})
+	}
+
+	t.Run("go flag", func(ts *testing.T) {
+		cel.RootPath = ts.TempDir()
+		flags = map[string]bool{"go": true}
+		ts.Cleanup(func() { flags = map[string]bool{} })
+		dir := filepath.Join(cel.RootPath, "migrations")
+		if err := os.Mkdir(dir, 0755); err != nil {
+			ts.Fatal(err)
+		}
+
+		if err := doMake("migration", "BackfillSlugs"); err != nil {
+			ts.Fatalf("doMake() unexpected error: %v", err)
+		}
+		files, _ := filepath.Glob(filepath.Join(dir, "*"))
+		if len(files) != 1 || !strings.HasSuffix(files[0], "_backfill_slugs.go") {
+			ts.Fatalf("doMake() wrote %v, want one <version>_backfill_slugs.go file", files)
+		}
+		version := strings.TrimSuffix(filepath.Base(files[0]), "_backfill_slugs.go")
+		got, err := os.ReadFile(files[0])
+		if err != nil {
+			ts.Fatal(err)
+		}
+		for _, want := range []string{
+			"celeritas.RegisterMigration(" + version + `, "backfill_slugs", upBackfillSlugs, downBackfillSlugs)`,
+			"func upBackfillSlugs(ctx context.Context, tx *sql.Tx) error",
+		} {
+			if !strings.Contains(string(got), want) {
+				ts.Errorf("%s missing %q in:\n%s", filepath.Base(files[0]), want, got)
+			}
+		}
+	})
+}
@@ -1,7 +1,13 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	celeritas "github.com/polyglotdev/celeritasproject"
 )
 
 // doMigrate handles database migration operations with input validation and helpful error messages.
@@ -14,14 +20,27 @@ import (
 //   - "reset": Performs a complete database reset by rolling back all migrations
 //     and then re-applying them, useful for development and testing environments
 //
+// and commands to inspect and repair the schema version:
+//   - "status": Lists every migration as applied, pending or dirty
+//   - "version": Prints the current schema version
+//   - "goto <version>": Migrates up or down to the given version
+//   - "steps <n>": Applies n migrations, or rolls back -n when n is negative
+//   - "force <version>": Records the version without running migrations,
+//     clearing the dirty flag after a failed migration has been fixed by hand
+//
+// With --dry-run, "up", "down" and "steps" print the version, file and SQL of
+// each migration they would run, in order, without changing the database,
+// and fail when there is anything to run so deploy checks can use it.
+//
 // The function includes input validation that:
 //   - Validates subcommands against a known list of valid options
 //   - Provides helpful suggestions for mistyped commands using string similarity
 //   - Returns clear error messages showing valid subcommand options
 //
 // Parameters:
-//   - arg2: The migration subcommand ("up", "down", or "reset")
-//   - arg3: Additional parameter for the "down" command ("all" to reverse all migrations)
+//   - arg2: The migration subcommand
+//   - arg3: "all" for "down" to reverse all migrations, or the version or
+//     count for "goto", "steps" and "force"
 //
 // Returns:
 //   - error: Returns nil on successful migration, otherwise returns either:
@@ -32,50 +51,58 @@ import (
 // to maintain consistent database connectivity across all migration operations.
 // Each operation is designed to be atomic - either completely succeeding or
 // failing with appropriate error information.
+//
+// The migrations themselves are run by celeritas.RunMigrations, which the
+// migration runner template calls through goRun when the migrations folder
+// has any Go migrations.
 func doMigrate(arg2, arg3 string) error {
-	validSubcommands := []string{"up", "down", "reset"}
+	validSubcommands := []string{"up", "down", "reset", "status", "version", "goto", "steps", "force"}
 	if !contains(validSubcommands, arg2) {
 		suggestion := findClosestMatch(arg2, validSubcommands)
 		if suggestion != "" {
 			return fmt.Errorf("invalid migrate subcommand: %s\nDid you mean '%s'?", arg2, suggestion)
 		}
 		return fmt.Errorf(
-			"invalid migrate subcommand: %s\nValid subcommands are: up, down, reset",
-			arg2,
+			"invalid migrate subcommand: %s\nValid subcommands are: %s",
+			arg2, strings.Join(validSubcommands, ", "),
 		)
 	}
 
-	dsn := getDSN()
-
-	switch arg2 {
-	case "up":
-		err := cel.MigrateUp(dsn)
-		if err != nil {
-			return err
+	var out bytes.Buffer
+	var err error
+	if goFiles, _ := filepath.Glob(filepath.Join(cel.RootPath, "migrations", "*.go")); len(goFiles) > 0 {
+		var stdout []byte
+		args := []string{arg2, arg3}
+		if hasFlag("dry-run") {
+			args = append(args, "--dry-run")
 		}
-
-	case "down":
-		if arg3 == "all" {
-			err := cel.MigrateDownAll(dsn)
-			if err != nil {
-				return err
-			}
-		} else {
-			err := cel.Steps(-1, dsn)
-			if err != nil {
-				return err
-			}
-		}
-
-	case "reset":
-		err := cel.MigrateDownAll(dsn)
+		stdout, err = goRun("templates/migrations/runner.go.txt", args...)
+		out.Write(stdout)
 		if err != nil {
-			return err
+			err = fmt.Errorf("running Go migrations: %w", err)
 		}
-		err = cel.MigrateUp(dsn)
-		if err != nil {
-			return err
+	} else {
+		err = celeritas.RunMigrations(cel.RootPath, &out, arg2, arg3, hasFlag("dry-run"))
+	}
+	printMigrationOutput(out.String())
+	return err
+}
+
+// printMigrationOutput prints the report of migrate status or version, or the
+// plan of a dry run, with a coloured state, so pending and dirty migrations
+// stand out. The SQL of a dry run is printed as it is.
+func printMigrationOutput(out string) {
+	for line := range strings.Lines(out) {
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "dirty"), strings.Contains(line, "(dirty"):
+			color.Red("%s", line)
+		case strings.HasPrefix(line, "pending"), strings.HasPrefix(line, "No "), strings.HasPrefix(line, "-- migration "):
+			color.Yellow("%s", line)
+		case strings.HasPrefix(line, "applied"), strings.HasPrefix(line, "Version "):
+			color.Green("%s", line)
+		default:
+			fmt.Println(line)
 		}
 	}
-	return nil
 }
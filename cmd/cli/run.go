@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// goRun compiles and runs a small program inside the application's module, so
+// that it can import the application's own packages. The CLI cannot link
+// code the developer writes, such as Go seeders and Go migrations, so the
+// commands that need it run through goRun whenever the application has any,
+// and do the work in-process otherwise. goRun generates a main package that
+// imports them into a temporary directory under the root path and runs it
+// with `go run`. The directory name starts with a dot, which
+// keeps it out of the developer's ./... builds while it exists.
+//
+// $MODULE$ in the template is replaced with the module path from go.mod.
+// The program's standard error is passed through and its standard output is
+// returned.
+func goRun(templatePath string, args ...string) ([]byte, error) {
+	module, err := modulePath(cel.RootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := templateFS.ReadFile(templatePath)
+	if err != nil {
+		return nil, err
+	}
+	program := strings.ReplaceAll(string(data), "$MODULE$", module)
+
+	dir, err := os.MkdirTemp(cel.RootPath, ".celeritas-run-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := copyDataToFile([]byte(program), filepath.Join(dir, "main.go")); err != nil {
+		return nil, err
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command("go", append([]string{"run", "./" + filepath.Base(dir)}, args...)...)
+	cmd.Dir = cel.RootPath
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	return stdout.Bytes(), err
+}
+
+// modulePath reads the module path from the go.mod file in rootPath.
+func modulePath(rootPath string) (string, error) {
+	file, err := os.Open(filepath.Join(rootPath, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New("go.mod has no module directive")
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	celeritas "github.com/polyglotdev/celeritasproject"
+)
+
+// doDB handles the db command, which works on the data in the database
+// rather than its schema. It returns the message to show on success.
+func doDB(arg2, arg3 string) (string, error) {
+	validSubcommands := []string{"seed"}
+	if !contains(validSubcommands, arg2) {
+		suggestion := findClosestMatch(arg2, validSubcommands)
+		if suggestion != "" {
+			return "", fmt.Errorf("invalid db subcommand: %s\nDid you mean '%s'?", arg2, suggestion)
+		}
+		return "", fmt.Errorf("invalid db subcommand: %s\nValid subcommands are: seed", arg2)
+	}
+
+	switch arg2 {
+	case "seed":
+		return doSeed(arg3)
+	}
+	return "", nil
+}
+
+// doSeed runs the seeders in the seeders folder, or only the one named. Seeders
+// that have already run are recorded in the seeds table and skipped, so it is
+// safe to run after every new seeder is added.
+//
+// When the seeders folder has any Go seeders, all of the seeders run through
+// goRun, with --force passed on.
+func doSeed(name string) (string, error) {
+	var names []string
+	if name != "" {
+		names = append(names, name)
+	}
+
+	var ran []string
+	if goFiles, _ := filepath.Glob(filepath.Join(cel.RootPath, "seeders", "*.go")); len(goFiles) > 0 {
+		args := names
+		if hasFlag("force") {
+			args = append(args, "--force")
+		}
+		out, err := goRun("templates/seeders/runner.go.txt", args...)
+		ran = strings.Fields(string(out))
+		printSeeded(ran)
+		if err != nil {
+			return "", fmt.Errorf("running Go seeders: %w", err)
+		}
+	} else {
+		var err error
+		ran, err = celeritas.RunSeeders(context.Background(), cel.RootPath, celeritas.SeedOptions{
+			Names: names,
+			Force: hasFlag("force"),
+		})
+		printSeeded(ran)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if len(ran) == 0 {
+		return "Nothing to seed", nil
+	}
+	return fmt.Sprintf("Seeding completed: %d seeder(s) run", len(ran)), nil
+}
+
+func printSeeded(names []string) {
+	for _, name := range names {
+		color.Green("seeded %s", name)
+	}
+}
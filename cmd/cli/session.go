@@ -30,15 +30,7 @@ import (
 //
 // It returns an error if file operations fail, ensuring atomic migration file creation.
 func doSessionTable() error {
-	dbType := cel.DB.DataType
-
-	if dbType == "mariadb" {
-		dbType = "mysql"
-	}
-
-	if dbType == "postgresql" {
-		dbType = "postgres"
-	}
+	dbType := migrationDialect(cel.DB.DataType)
 
 	fileName := fmt.Sprintf("%d_create_sessions_table", time.Now().UnixMicro())
 
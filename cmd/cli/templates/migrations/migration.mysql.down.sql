@@ -0,0 +1,4 @@
+-- MySQL and MariaDB rollback. Undo everything the matching .up.sql file does,
+-- in reverse order.
+
+-- DROP TABLE IF EXISTS some_table;
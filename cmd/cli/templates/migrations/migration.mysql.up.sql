@@ -0,0 +1,13 @@
+-- MySQL and MariaDB migration. Everything in this file runs when the migration
+-- is applied; the matching .down.sql file must undo it. Note that MySQL
+-- commits implicitly after each CREATE, ALTER and DROP, so a migration that
+-- fails part way is not rolled back.
+
+-- CREATE TABLE some_table (
+--     id int unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+--     some_field varchar(255) NOT NULL,
+--     created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+--     updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+-- ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- CREATE INDEX some_table_some_field_idx ON some_table (some_field);
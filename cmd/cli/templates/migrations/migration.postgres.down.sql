@@ -1 +1,5 @@
--- drop table some_table;
\ No newline at end of file
+-- PostgreSQL rollback. Undo everything the matching .up.sql file does, in
+-- reverse order.
+
+-- DROP TRIGGER IF EXISTS set_timestamp ON some_table;
+-- DROP TABLE IF EXISTS some_table;
@@ -1,21 +1,24 @@
+-- PostgreSQL migration. Everything in this file runs when the migration is
+-- applied; the matching .down.sql file must undo it.
+
 -- CREATE TABLE some_table (
 --     id serial PRIMARY KEY,
---     some_field VARCHAR ( 255 ) NOT NULL,
---     created_at TIMESTAMP,
---     updated_at TIMESTAMP
+--     some_field varchar(255) NOT NULL,
+--     created_at timestamp NOT NULL DEFAULT now(),
+--     updated_at timestamp NOT NULL DEFAULT now()
 -- );
 
--- add auto update of updated_at. If you already have this trigger
--- you can delete the next 7 lines
+-- Keep updated_at current. If an earlier migration already created the
+-- trigger_set_timestamp function, only the CREATE TRIGGER statement is needed.
 -- CREATE OR REPLACE FUNCTION trigger_set_timestamp()
 -- RETURNS TRIGGER AS $$
 -- BEGIN
 --   NEW.updated_at = NOW();
--- RETURN NEW;
+--   RETURN NEW;
 -- END;
 -- $$ LANGUAGE plpgsql;
 
 -- CREATE TRIGGER set_timestamp
 --     BEFORE UPDATE ON some_table
 --     FOR EACH ROW
---     EXECUTE PROCEDURE trigger_set_timestamp();
\ No newline at end of file
+--     EXECUTE PROCEDURE trigger_set_timestamp();
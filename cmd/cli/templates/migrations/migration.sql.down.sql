@@ -0,0 +1,4 @@
+-- Dialect-neutral rollback. Undo everything the matching .up.sql file does,
+-- in reverse order.
+
+-- DROP TABLE some_table;
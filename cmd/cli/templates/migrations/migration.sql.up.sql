@@ -0,0 +1,11 @@
+-- Dialect-neutral migration, applied whatever DATABASE_TYPE is set to. Stick
+-- to SQL every supported database accepts; write a dialect-specific migration
+-- for auto-increment keys, triggers and other features whose syntax differs.
+-- The matching .down.sql file must undo this one.
+
+-- CREATE TABLE some_table (
+--     some_field varchar(255) NOT NULL,
+--     created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+-- );
+
+-- CREATE INDEX some_table_some_field_idx ON some_table (some_field);
@@ -0,0 +1,4 @@
+-- SQLite rollback. Undo everything the matching .up.sql file does, in reverse
+-- order. Dropping a table also drops its triggers.
+
+-- DROP TABLE IF EXISTS some_table;
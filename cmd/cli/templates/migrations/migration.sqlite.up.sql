@@ -0,0 +1,17 @@
+-- SQLite migration. Everything in this file runs when the migration is
+-- applied; the matching .down.sql file must undo it. SQLite's ALTER TABLE
+-- cannot change or drop constraints; create a new table and copy the rows
+-- across instead.
+
+-- CREATE TABLE some_table (
+--     id INTEGER PRIMARY KEY AUTOINCREMENT,
+--     some_field TEXT NOT NULL,
+--     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+--     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+-- );
+
+-- Keep updated_at current.
+-- CREATE TRIGGER some_table_updated_at AFTER UPDATE ON some_table
+-- BEGIN
+--     UPDATE some_table SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+-- END;
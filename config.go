@@ -0,0 +1,421 @@
+package celeritas
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Environment profiles selected with APP_ENV.
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Config holds every setting Celeritas reads from the environment. It is
+// populated by LoadConfig from the application's .env files and the process
+// environment, with the process environment taking precedence.
+//
+// Each field is bound to an environment variable with the env struct tag.
+// The optional default tag supplies a value when the variable is unset, and
+// required:"true" makes a missing variable a configuration error. Nested
+// structs are walked recursively.
+type Config struct {
+	AppName         string        `env:"APP_NAME" default:"celeritas"`
+	Env             string        `env:"APP_ENV" default:"development"`
+	Debug           bool          `env:"DEBUG"` // defaults to true only in development
+	Port            string        `env:"PORT" default:"4000"`
+	Renderer        string        `env:"RENDERER" default:"jet"`
+	Key             string        `env:"KEY"`
+	Cache           string        `env:"CACHE"`
+	SessionType     string        `env:"SESSION_TYPE" default:"cookie"`
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"30s"`
+	Cookie          CookieConfig
+	Database        DatabaseConfig
+	Redis           RedisConfig
+	Log             LogConfig
+	AccessLog       AccessLogConfig
+	Metrics         MetricsConfig
+	Health          HealthConfig
+	Server          ServerConfig
+	TLS             TLSConfig
+}
+
+// CookieConfig holds the settings for the session cookie.
+type CookieConfig struct {
+	Name     string `env:"COOKIE_NAME" default:"celeritas"`
+	Lifetime int    `env:"COOKIE_LIFETIME" default:"60"` // minutes
+	Persist  bool   `env:"COOKIE_PERSIST"`
+	Secure   bool   `env:"COOKIE_SECURE"`
+	Domain   string `env:"COOKIE_DOMAIN"`
+}
+
+// DatabaseConfig holds the settings used to connect to the database. An
+// empty Type means the application runs without a database.
+type DatabaseConfig struct {
+	Type     string `env:"DATABASE_TYPE"`
+	Host     string `env:"DATABASE_HOST" default:"localhost"`
+	Port     int    `env:"DATABASE_PORT"`
+	User     string `env:"DATABASE_USER"`
+	Password string `env:"DATABASE_PASS"`
+	Name     string `env:"DATABASE_NAME"`
+	Path     string `env:"DATABASE_PATH"` // SQLite database file, relative to the root path
+
+	// SSLMode uses the PostgreSQL names (disable, prefer, require,
+	// verify-ca, verify-full) for every database type.
+	SSLMode string `env:"DATABASE_SSL_MODE" default:"disable"`
+
+	// MySQL and MariaDB connection settings. TimeZone is also sent to
+	// PostgreSQL as the session time zone.
+	Charset   string `env:"DATABASE_CHARSET" default:"utf8mb4"`
+	Collation string `env:"DATABASE_COLLATION"`
+	TimeZone  string `env:"DATABASE_TIMEZONE" default:"UTC"`
+	ParseTime bool   `env:"DATABASE_PARSE_TIME" default:"true"`
+
+	// Connection pool settings applied to the pool Celeritas opens. As in
+	// database/sql, a zero MaxOpen or lifetime means no limit, and a zero
+	// MaxIdle keeps no idle connections.
+	MaxOpen         int           `env:"DATABASE_MAX_OPEN" default:"25"`
+	MaxIdle         int           `env:"DATABASE_MAX_IDLE" default:"25"`
+	ConnMaxLifetime time.Duration `env:"DATABASE_CONN_MAX_LIFETIME" default:"5m"`
+	ConnMaxIdleTime time.Duration `env:"DATABASE_CONN_MAX_IDLE_TIME" default:"5m"`
+
+	// RetryTimeout is how long startup keeps retrying, with exponential
+	// backoff, while the database is unreachable, so an application started
+	// alongside its database waits for it by default. Zero tries once.
+	RetryTimeout time.Duration `env:"DATABASE_RETRY_TIMEOUT" default:"30s"`
+
+	Replicas    ReplicaConfig
+	QueryLog    QueryLogConfig
+	AutoMigrate AutoMigrateConfig
+}
+
+// RedisConfig holds the settings for the Redis connection pool.
+type RedisConfig struct {
+	Host     string `env:"REDIS_HOST"`
+	Password string `env:"REDIS_PASSWORD"`
+	Prefix   string `env:"REDIS_PREFIX"`
+}
+
+// ConfigError reports every missing or malformed setting found while loading
+// the configuration, so they can all be fixed in one pass.
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// LoadConfig reads the layered .env files in rootPath, overlays the process
+// environment and decodes the result into a Config. Missing .env files are
+// not an error, so settings may come entirely from the environment.
+//
+// The files are read in this order, each overriding the one before:
+//
+//  1. .env
+//  2. .env.{APP_ENV}, for example .env.production
+//  3. .env.local, for machine-specific overrides (skipped when APP_ENV is test
+//     so test runs are reproducible)
+//
+// APP_ENV itself is taken from the process environment or, failing that, from
+// .env, and defaults to development. Unless DEBUG is set explicitly, it is
+// enabled in development and disabled in every other profile.
+//
+// If any setting is missing or malformed, LoadConfig returns a *ConfigError
+// listing all of them.
+func LoadConfig(rootPath string) (Config, error) {
+	files, err := envFiles(rootPath)
+	if err != nil {
+		return Config{}, err
+	}
+
+	env, err := readEnv(files...)
+	if err != nil {
+		return Config{}, err
+	}
+	lookup := lookupIn(env)
+
+	var cfg Config
+	problems := decodeEnv(&cfg, lookup)
+	if _, ok := lookup("DEBUG"); !ok {
+		cfg.Debug = cfg.Env == EnvDevelopment
+	}
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return cfg, &ConfigError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// envFiles returns the .env files for the active profile in rootPath, from
+// lowest to highest precedence. Files that do not exist are included; readEnv
+// skips them.
+func envFiles(rootPath string) ([]string, error) {
+	base := filepath.Join(rootPath, ".env")
+
+	profile := strings.TrimSpace(os.Getenv("APP_ENV"))
+	if profile == "" {
+		values, err := godotenv.Read(base)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("reading %s: %w", base, err)
+		}
+		profile = strings.TrimSpace(values["APP_ENV"])
+	}
+	if profile == "" {
+		profile = EnvDevelopment
+	}
+
+	files := []string{base, base + "." + profile}
+	if profile != EnvTest {
+		files = append(files, base+".local")
+	}
+	return files, nil
+}
+
+// readEnv returns the variables in the given .env files merged with the
+// process environment. Later files override earlier ones and the process
+// environment overrides every file, except where it is set but empty.
+func readEnv(files ...string) (map[string]string, error) {
+	env := make(map[string]string)
+	for _, file := range files {
+		values, err := godotenv.Read(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		for k, v := range values {
+			env[k] = v
+		}
+	}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok && v != "" {
+			env[k] = v
+		}
+	}
+	return env, nil
+}
+
+// lookupIn adapts a map to the lookup function used by decodeEnv. Empty
+// values are treated as unset so that "PORT=" falls back to the default.
+func lookupIn(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		if !ok || strings.TrimSpace(v) == "" {
+			return "", false
+		}
+		return v, true
+	}
+}
+
+// validate checks settings that depend on one another and so cannot be
+// expressed with struct tags.
+func (cfg Config) validate() []string {
+	var problems []string
+
+	profiles := []string{EnvDevelopment, EnvTest, EnvStaging, EnvProduction}
+	if !slices.Contains(profiles, cfg.Env) {
+		problems = append(problems, fmt.Sprintf(
+			"APP_ENV: must be one of %s (got %q)", strings.Join(profiles, ", "), cfg.Env))
+	}
+
+	if !slices.Contains([]string{"go", "jet"}, cfg.Renderer) {
+		problems = append(problems, fmt.Sprintf("RENDERER: must be one of go, jet (got %q)", cfg.Renderer))
+	}
+
+	sessionTypes := []string{"cookie", "redis", "mysql", "mariadb", "postgres", "postgresql", "sqlite"}
+	if !slices.Contains(sessionTypes, cfg.SessionType) {
+		problems = append(problems, fmt.Sprintf(
+			"SESSION_TYPE: must be one of %s (got %q)", strings.Join(sessionTypes, ", "), cfg.SessionType))
+	}
+
+	if cfg.Database.Type != "" {
+		dbTypes := []string{"postgres", "postgresql", "pgx", "mysql", "mariadb", "sqlite"}
+		if !slices.Contains(dbTypes, cfg.Database.Type) {
+			problems = append(problems, fmt.Sprintf(
+				"DATABASE_TYPE: must be one of %s (got %q)", strings.Join(dbTypes, ", "), cfg.Database.Type))
+		}
+		if cfg.Database.Type == "sqlite" {
+			if cfg.Database.Path == "" {
+				problems = append(problems, "DATABASE_PATH: required when DATABASE_TYPE is sqlite")
+			}
+		} else {
+			if cfg.Database.User == "" {
+				problems = append(problems, "DATABASE_USER: required when DATABASE_TYPE is set")
+			}
+			if cfg.Database.Name == "" {
+				problems = append(problems, "DATABASE_NAME: required when DATABASE_TYPE is set")
+			}
+		}
+		sslModes := []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+		if !slices.Contains(sslModes, cfg.Database.SSLMode) {
+			problems = append(problems, fmt.Sprintf(
+				"DATABASE_SSL_MODE: must be one of %s (got %q)", strings.Join(sslModes, ", "), cfg.Database.SSLMode))
+		}
+		if _, err := time.LoadLocation(cfg.Database.TimeZone); err != nil {
+			problems = append(problems, fmt.Sprintf("DATABASE_TIMEZONE: unknown time zone %q", cfg.Database.TimeZone))
+		}
+		for _, setting := range []struct {
+			key   string
+			value int64
+		}{
+			{"DATABASE_MAX_OPEN", int64(cfg.Database.MaxOpen)},
+			{"DATABASE_MAX_IDLE", int64(cfg.Database.MaxIdle)},
+			{"DATABASE_CONN_MAX_LIFETIME", int64(cfg.Database.ConnMaxLifetime)},
+			{"DATABASE_CONN_MAX_IDLE_TIME", int64(cfg.Database.ConnMaxIdleTime)},
+			{"DATABASE_RETRY_TIMEOUT", int64(cfg.Database.RetryTimeout)},
+		} {
+			if setting.value < 0 {
+				problems = append(problems, setting.key+": must not be negative")
+			}
+		}
+		problems = append(problems, cfg.Database.Replicas.validate(cfg.Database.Type)...)
+		problems = append(problems, cfg.Database.QueryLog.validate()...)
+	}
+	problems = append(problems, cfg.Database.AutoMigrate.validate(cfg.Database.Type)...)
+
+	switch cfg.SessionType {
+	case "mysql", "mariadb", "postgres", "postgresql", "sqlite":
+		if cfg.Database.Type == "" {
+			problems = append(problems, fmt.Sprintf("DATABASE_TYPE: required when SESSION_TYPE is %s", cfg.SessionType))
+		}
+	}
+
+	if (cfg.Cache == "redis" || cfg.SessionType == "redis") && cfg.Redis.Host == "" {
+		problems = append(problems, "REDIS_HOST: required when CACHE or SESSION_TYPE is redis")
+	}
+
+	problems = append(problems, cfg.Log.validate()...)
+	problems = append(problems, cfg.AccessLog.validate()...)
+	problems = append(problems, cfg.Health.validate()...)
+	problems = append(problems, cfg.Server.validate()...)
+	problems = append(problems, cfg.TLS.validate()...)
+
+	if cfg.Metrics.Enabled && !strings.HasPrefix(cfg.Metrics.Path, "/") {
+		problems = append(problems, fmt.Sprintf("METRICS_PATH: must start with / (got %q)", cfg.Metrics.Path))
+	}
+
+	return problems
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// decodeEnv fills the struct pointed to by dst from the env, default and
+// required struct tags, returning one message per missing or malformed
+// setting.
+func decodeEnv(dst any, lookup func(string) (string, bool)) []string {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return []string{fmt.Sprintf("decodeEnv: expected pointer to struct, got %T", dst)}
+	}
+	return decodeStruct(v.Elem(), lookup)
+}
+
+func decodeStruct(v reflect.Value, lookup func(string) (string, bool)) []string {
+	var problems []string
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+
+		key, ok := field.Tag.Lookup("env")
+		if !ok {
+			if field.Type.Kind() == reflect.Struct && field.Type != durationType {
+				problems = append(problems, decodeStruct(fv, lookup)...)
+			}
+			continue
+		}
+
+		raw, set := lookup(key)
+		if !set {
+			if field.Tag.Get("required") == "true" {
+				problems = append(problems, fmt.Sprintf("%s: required but not set", key))
+				continue
+			}
+			raw, set = field.Tag.Lookup("default")
+			if !set {
+				continue
+			}
+		}
+
+		if err := setField(fv, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v (got %q)", key, err, raw))
+		}
+	}
+
+	return problems
+}
+
+// setField parses raw according to the kind of fv and stores the result.
+func setField(fv reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return errors.New("must be a duration such as 30s or 5m")
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return errors.New("must be a non-negative integer")
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, fv.Type().Bits())
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", fv.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		fv.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,270 @@
+package celeritas
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeEnv(t *testing.T) {
+	type nested struct {
+		Ratio float64 `env:"RATIO" default:"0.5"`
+	}
+	type settings struct {
+		Name    string        `env:"NAME" required:"true"`
+		Port    int           `env:"PORT" default:"4000"`
+		Debug   bool          `env:"DEBUG"`
+		Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+		Hosts   []string      `env:"HOSTS"`
+		Nested  nested
+	}
+
+	tests := []struct {
+		name         string
+		env          map[string]string
+		want         settings
+		wantProblems []string
+	}{
+		{
+			name: "defaults applied",
+			env:  map[string]string{"NAME": "app"},
+			want: settings{Name: "app", Port: 4000, Timeout: 5 * time.Second, Nested: nested{Ratio: 0.5}},
+		},
+		{
+			name: "values parsed",
+			env: map[string]string{
+				"NAME":    "app",
+				"PORT":    "8080",
+				"DEBUG":   "true",
+				"TIMEOUT": "1m",
+				"HOSTS":   "a:1, b:2,",
+				"RATIO":   "0.25",
+			},
+			want: settings{
+				Name:    "app",
+				Port:    8080,
+				Debug:   true,
+				Timeout: time.Minute,
+				Hosts:   []string{"a:1", "b:2"},
+				Nested:  nested{Ratio: 0.25},
+			},
+		},
+		{
+			name: "every problem reported",
+			env: map[string]string{
+				"PORT":    "eighty",
+				"DEBUG":   "yes please",
+				"TIMEOUT": "30",
+			},
+			wantProblems: []string{
+				"NAME: required but not set",
+				`PORT: must be an integer (got "eighty")`,
+				`DEBUG: must be true or false (got "yes please")`,
+				`TIMEOUT: must be a duration such as 30s or 5m (got "30")`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			var got settings
+			problems := decodeEnv(&got, lookupIn(tt.env))
+
+			if !reflect.DeepEqual(problems, tt.wantProblems) {
+				ts.Errorf("decodeEnv() problems = %q, want %q", problems, tt.wantProblems)
+			}
+			if tt.wantProblems == nil && !reflect.DeepEqual(got, tt.want) {
+				ts.Errorf("decodeEnv() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		dotEnv       string
+		env          map[string]string
+		check        func(ts *testing.T, cfg Config)
+		wantProblems []string
+	}{
+		{
+			name:   "dot env file with defaults",
+			dotEnv: "PORT=9000\nDEBUG=true\n",
+			check: func(ts *testing.T, cfg Config) {
+				if cfg.Port != "9000" || !cfg.Debug {
+					ts.Errorf("LoadConfig() Port = %q, Debug = %v; want 9000, true", cfg.Port, cfg.Debug)
+				}
+				if cfg.AppName != "celeritas" || cfg.Renderer != "jet" || cfg.SessionType != "cookie" {
+					ts.Errorf("LoadConfig() defaults not applied: %+v", cfg)
+				}
+				if cfg.ShutdownTimeout != 30*time.Second {
+					ts.Errorf("LoadConfig() ShutdownTimeout = %v, want 30s", cfg.ShutdownTimeout)
+				}
+				if cfg.Database.RetryTimeout != 30*time.Second {
+					ts.Errorf("LoadConfig() Database.RetryTimeout = %v, want 30s", cfg.Database.RetryTimeout)
+				}
+			},
+		},
+		{
+			name:   "environment overrides dot env file",
+			dotEnv: "PORT=9000\n",
+			env:    map[string]string{"PORT": "9001"},
+			check: func(ts *testing.T, cfg Config) {
+				if cfg.Port != "9001" {
+					ts.Errorf("LoadConfig() Port = %q, want 9001", cfg.Port)
+				}
+			},
+		},
+		{
+			name: "dependent settings validated together",
+			dotEnv: "DATABASE_TYPE=postgres\nDATABASE_PORT=abc\n" +
+				"SESSION_TYPE=redis\nRENDERER=pug\n",
+			wantProblems: []string{
+				`DATABASE_PORT: must be an integer (got "abc")`,
+				`RENDERER: must be one of go, jet (got "pug")`,
+				"DATABASE_USER: required when DATABASE_TYPE is set",
+				"DATABASE_NAME: required when DATABASE_TYPE is set",
+				"REDIS_HOST: required when CACHE or SESSION_TYPE is redis",
+			},
+		},
+		{
+			name: "mysql connection settings validated",
+			dotEnv: "DATABASE_TYPE=mariadb\nDATABASE_USER=app\nDATABASE_NAME=shop\n" +
+				"DATABASE_SSL_MODE=on\nDATABASE_TIMEZONE=Mars/Olympus\n",
+			wantProblems: []string{
+				`DATABASE_SSL_MODE: must be one of disable, allow, prefer, require, verify-ca, verify-full (got "on")`,
+				`DATABASE_TIMEZONE: unknown time zone "Mars/Olympus"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			for _, key := range []string{"PORT", "DEBUG", "RENDERER", "SESSION_TYPE", "DATABASE_TYPE", "CACHE", "APP_NAME"} {
+				ts.Setenv(key, "")
+			}
+			for k, v := range tt.env {
+				ts.Setenv(k, v)
+			}
+
+			dir := ts.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, ".env"), []byte(tt.dotEnv), 0644); err != nil {
+				ts.Fatal(err)
+			}
+
+			cfg, err := LoadConfig(dir)
+
+			if tt.wantProblems != nil {
+				var cfgErr *ConfigError
+				if !errors.As(err, &cfgErr) {
+					ts.Fatalf("LoadConfig() error = %v, want *ConfigError", err)
+				}
+				if !reflect.DeepEqual(cfgErr.Problems, tt.wantProblems) {
+					ts.Errorf("LoadConfig() problems =\n%s\nwant\n%s",
+						strings.Join(cfgErr.Problems, "\n"), strings.Join(tt.wantProblems, "\n"))
+				}
+				return
+			}
+
+			if err != nil {
+				ts.Fatalf("LoadConfig() unexpected error: %v", err)
+			}
+			tt.check(ts, cfg)
+		})
+	}
+}
+
+func TestLoadConfig_MissingDotEnv(t *testing.T) {
+	t.Setenv("PORT", "7000")
+
+	cfg, err := LoadConfig(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+	if cfg.Port != "7000" {
+		t.Errorf("LoadConfig() Port = %q, want 7000", cfg.Port)
+	}
+}
+
+func TestLoadConfig_Profiles(t *testing.T) {
+	files := map[string]string{
+		".env":            "APP_ENV=staging\nPORT=1000\nAPP_NAME=base\nKEY=base\n",
+		".env.staging":    "PORT=2000\nAPP_NAME=staging\n",
+		".env.production": "PORT=3000\n",
+		".env.test":       "PORT=4000\n",
+		".env.local":      "APP_NAME=local\n",
+	}
+
+	tests := []struct {
+		name        string
+		appEnv      string
+		wantEnv     string
+		wantPort    string
+		wantAppName string
+		wantDebug   bool
+	}{
+		{
+			name:        "profile from dot env file",
+			wantEnv:     EnvStaging,
+			wantPort:    "2000",
+			wantAppName: "local",
+		},
+		{
+			name:        "profile from environment",
+			appEnv:      EnvProduction,
+			wantEnv:     EnvProduction,
+			wantPort:    "3000",
+			wantAppName: "local",
+		},
+		{
+			name:        "local overrides skipped in test",
+			appEnv:      EnvTest,
+			wantEnv:     EnvTest,
+			wantPort:    "4000",
+			wantAppName: "base",
+		},
+		{
+			name:        "debug defaults on in development",
+			appEnv:      EnvDevelopment,
+			wantEnv:     EnvDevelopment,
+			wantPort:    "1000",
+			wantAppName: "local",
+			wantDebug:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			for _, key := range []string{"PORT", "DEBUG", "RENDERER", "SESSION_TYPE", "DATABASE_TYPE", "CACHE", "APP_NAME", "KEY"} {
+				ts.Setenv(key, "")
+			}
+			ts.Setenv("APP_ENV", tt.appEnv)
+
+			dir := ts.TempDir()
+			for name, contents := range files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+					ts.Fatal(err)
+				}
+			}
+
+			cfg, err := LoadConfig(dir)
+			if err != nil {
+				ts.Fatalf("LoadConfig() unexpected error: %v", err)
+			}
+
+			if cfg.Env != tt.wantEnv || cfg.Port != tt.wantPort || cfg.AppName != tt.wantAppName || cfg.Debug != tt.wantDebug {
+				ts.Errorf("LoadConfig() Env = %q, Port = %q, AppName = %q, Debug = %v; want %q, %q, %q, %v",
+					cfg.Env, cfg.Port, cfg.AppName, cfg.Debug,
+					tt.wantEnv, tt.wantPort, tt.wantAppName, tt.wantDebug)
+			}
+			if cfg.Key != "base" {
+				ts.Errorf("LoadConfig() Key = %q, want value from .env", cfg.Key)
+			}
+		})
+	}
+}
@@ -1,33 +1,267 @@
 package celeritas
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	_ "github.com/jackc/pgconn"
 	_ "github.com/jackc/pgx/v4"
 	_ "github.com/jackc/pgx/v4/stdlib"
+	_ "modernc.org/sqlite"
 )
 
 func (c *Celeritas) OpenDB(dbType, dsn string) (*sql.DB, error) {
-	// The if statement is used because we specify the driver type in
-	// the .env file, and to avoid the issue of user confusion on postgres
-	// vs pgx, because pgx is what we need to connect to postgres.
-	if dbType == "postgres" || dbType == "postgresql" {
-		dbType = "pgx"
-	}
-
-	db, err := sql.Open(dbType, dsn)
+	db, err := c.sqlOpen(dbType, dsn)
 	if err != nil {
 		return nil, err
 	}
 
+	// Every connection to an in-memory SQLite database gets a database of
+	// its own, so the pool must hold on to a single connection.
+	if driverName(dbType) == "sqlite" && strings.Contains(dsn, ":memory:") {
+		db.SetMaxOpenConns(1)
+		db.SetConnMaxIdleTime(0)
+		db.SetConnMaxLifetime(0)
+	}
+
 	// We are pinging the database to ensure that the connection is
 	// working and that the database is up and running.
 	err = db.Ping()
 	if err != nil {
+		_ = db.Close()
 		return nil, err
 	}
 
 	return db, nil
 
 }
+
+// Backoff bounds for connection retries at startup.
+const (
+	initialRetryDelay = 250 * time.Millisecond
+	maxRetryDelay     = 5 * time.Second
+)
+
+// connectDB opens the configured database and applies the pool settings. If
+// the database cannot be reached it retries with exponential backoff until
+// DATABASE_RETRY_TIMEOUT has passed or ctx is done, so an application started
+// alongside its database waits for it rather than failing.
+func (c *Celeritas) connectDB(ctx context.Context) (*sql.DB, error) {
+	cfg := c.config.Database
+	dsn := c.BuildDSN()
+	deadline := time.Now().Add(cfg.RetryTimeout)
+	delay := initialRetryDelay
+
+	for attempt := 1; ; attempt++ {
+		db, err := c.OpenDB(cfg.Type, dsn)
+		if err == nil {
+			cfg.applyPool(db)
+			return db, nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			if attempt > 1 {
+				return nil, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+			}
+			return nil, err
+		}
+
+		// the last attempt is made at the deadline rather than after it
+		wait := min(delay, remaining)
+		c.logger().Warn("database not ready, retrying",
+			"type", cfg.Type, "attempt", attempt, "retry_in", wait, "error", err)
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// applyPool applies the connection pool settings to db. In-memory SQLite
+// databases keep the single connection OpenDB gave them.
+func (d DatabaseConfig) applyPool(db *sql.DB) {
+	if d.Type == "sqlite" && d.Path == ":memory:" {
+		return
+	}
+	db.SetMaxOpenConns(d.MaxOpen)
+	db.SetMaxIdleConns(d.MaxIdle)
+	db.SetConnMaxLifetime(d.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(d.ConnMaxIdleTime)
+}
+
+// driverName maps a DATABASE_TYPE to the database/sql driver that serves it.
+// We specify the type in the .env file, and users reasonably write postgres or
+// mariadb rather than the driver names pgx and mysql.
+func driverName(dbType string) string {
+	switch dbType {
+	case "postgres", "postgresql":
+		return "pgx"
+	case "mariadb":
+		return "mysql"
+	case "sqlite":
+		return "sqlite"
+	}
+	return dbType
+}
+
+// DSN builds the datasource name for the configured database type. It
+// returns an empty string for types it does not know how to connect to.
+func (d DatabaseConfig) DSN() string {
+	var dsn string
+
+	switch d.Type {
+	case "postgres", "postgresql", "pgx":
+		dsn = fmt.Sprintf(
+			"host=%s user=%s dbname=%s sslmode=%s timezone=%s connect_timeout=5",
+			d.Host,
+			d.User,
+			d.Name,
+			d.SSLMode,
+			d.TimeZone,
+		)
+
+		if d.Port != 0 {
+			dsn = fmt.Sprintf("%s port=%d", dsn, d.Port)
+		}
+
+		if d.Password != "" {
+			dsn = fmt.Sprintf("%s password=%s", dsn, d.Password)
+		}
+
+	case "mysql", "mariadb":
+		dsn = d.mysqlConfig().FormatDSN()
+
+	case "sqlite":
+		dsn = d.sqliteDSN()
+
+	default:
+
+	}
+
+	return dsn
+}
+
+// sqlitePragmas are applied to every SQLite connection. Foreign keys are off
+// by default in SQLite, WAL lets readers run alongside a writer, and the busy
+// timeout makes concurrent writers wait for the lock instead of failing.
+var sqlitePragmas = []string{"foreign_keys(1)", "journal_mode(WAL)", "busy_timeout(5000)"}
+
+// sqliteDSN builds a modernc.org/sqlite datasource name for the database file
+// at Path.
+func (d DatabaseConfig) sqliteDSN() string {
+	params := url.Values{}
+	for _, pragma := range sqlitePragmas {
+		if d.Path == ":memory:" && strings.HasPrefix(pragma, "journal_mode") {
+			continue // in-memory databases cannot use WAL
+		}
+		params.Add("_pragma", pragma)
+	}
+	return "file:" + d.Path + "?" + params.Encode()
+}
+
+// withRoot returns a copy of d whose SQLite Path, if relative, is resolved
+// against rootPath rather than the working directory.
+func (d DatabaseConfig) withRoot(rootPath string) DatabaseConfig {
+	if d.Path != "" && d.Path != ":memory:" && !filepath.IsAbs(d.Path) {
+		d.Path = filepath.Join(rootPath, d.Path)
+	}
+	return d
+}
+
+// mysqlConfig translates the settings into a go-sql-driver configuration.
+func (d DatabaseConfig) mysqlConfig() *mysql.Config {
+	port := d.Port
+	if port == 0 {
+		port = 3306
+	}
+
+	loc, err := time.LoadLocation(d.TimeZone)
+	if err != nil {
+		loc = time.UTC // rejected by validate; only reachable for hand-built configs
+	}
+
+	cfg := mysql.NewConfig()
+	cfg.User = d.User
+	cfg.Passwd = d.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(d.Host, strconv.Itoa(port))
+	cfg.DBName = d.Name
+	cfg.Timeout = 5 * time.Second
+	cfg.ParseTime = d.ParseTime
+	cfg.Loc = loc
+	cfg.TLSConfig = mysqlTLS(d.SSLMode)
+	if d.Collation != "" {
+		cfg.Collation = d.Collation
+	}
+	if d.Charset != "" {
+		cfg.Params = map[string]string{"charset": d.Charset}
+	}
+	return cfg
+}
+
+// mysqlTLS translates a PostgreSQL sslmode into the MySQL driver's tls
+// parameter, so one setting works for both.
+func mysqlTLS(sslMode string) string {
+	switch sslMode {
+	case "allow", "prefer":
+		return "preferred"
+	case "require":
+		return "skip-verify"
+	case "verify-ca", "verify-full":
+		return "true"
+	}
+	return "false"
+}
+
+// MigrateURL builds the database URL used by golang-migrate. It returns an
+// empty string for types it does not know how to connect to.
+func (d DatabaseConfig) MigrateURL() string {
+	switch d.Type {
+	case "postgres", "postgresql", "pgx":
+		u := url.URL{
+			Scheme:   "postgres",
+			Host:     d.Host,
+			Path:     "/" + d.Name,
+			RawQuery: "sslmode=" + url.QueryEscape(d.SSLMode),
+		}
+		if d.Port != 0 {
+			u.Host = net.JoinHostPort(d.Host, strconv.Itoa(d.Port))
+		}
+		if d.Password != "" {
+			u.User = url.UserPassword(d.User, d.Password)
+		} else {
+			u.User = url.User(d.User)
+		}
+		return u.String()
+
+	case "mysql", "mariadb":
+		cfg := d.mysqlConfig()
+		// golang-migrate unescapes the credentials and only accepts boolean
+		// or skip-verify tls values; preferred falls back to plain text, so
+		// migrations do the same.
+		cfg.User = url.QueryEscape(cfg.User)
+		cfg.Passwd = url.QueryEscape(cfg.Passwd)
+		if cfg.TLSConfig == "preferred" {
+			cfg.TLSConfig = "false"
+		}
+		return "mysql://" + cfg.FormatDSN()
+
+	case "sqlite":
+		// golang-migrate opens everything after the scheme with the same
+		// modernc.org/sqlite driver, so the pragmas apply to migrations too
+		return "sqlite://" + strings.TrimPrefix(d.sqliteDSN(), "file:")
+	}
+	return ""
+}
@@ -0,0 +1,212 @@
+// Package factory builds model values for tests and seeders.
+//
+// A model defines its factory once, usually next to the model, with a
+// function that fills in default field values:
+//
+//	var Users = factory.Define(func(f *factory.Faker, seq int) User {
+//		return User{
+//			FirstName: f.FirstName(),
+//			Email:     f.Email(seq),
+//			Password:  "password",
+//		}
+//	}).Persist(func(ctx context.Context, u *User) (err error) {
+//		u.ID, err = u.Insert(*u)
+//		return err
+//	})
+//
+// Make builds values in memory; Create also persists them. Both accept
+// overrides applied after the defaults:
+//
+//	u := factory.Make[User]()
+//	admin, err := factory.Create(ctx, func(u *User) { u.Email = "admin@example.com" })
+//
+// Values are generated from a seeded source, so a test gets the same values
+// on every run. Reset reseeds every factory, for tests that must not depend
+// on the order in which they run.
+package factory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// DefaultSeed seeds factories until Reset is called.
+const DefaultSeed = 1
+
+// Factory builds values of type T.
+type Factory[T any] struct {
+	mu       sync.Mutex
+	defaults func(f *Faker, seq int) T
+	persist  func(ctx context.Context, v *T) error
+	parents  []func(ctx context.Context, v *T, create bool) error
+	faker    *Faker
+	seq      int
+}
+
+var registry = struct {
+	mu        sync.Mutex
+	factories map[reflect.Type]resetter
+}{factories: make(map[reflect.Type]resetter)}
+
+type resetter interface {
+	reset(seed uint64)
+}
+
+// Define creates the factory for T and registers it, so Make[T] and Create[T]
+// can find it. The defaults function is called for every value built, with
+// the factory's Faker and a sequence number that starts at 1. It panics if T
+// already has a factory.
+func Define[T any](defaults func(f *Faker, seq int) T) *Factory[T] {
+	fa := &Factory[T]{defaults: defaults, faker: NewFaker(DefaultSeed)}
+
+	t := reflect.TypeFor[T]()
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, dup := registry.factories[t]; dup {
+		panic(fmt.Sprintf("factory: Define called twice for %s", t))
+	}
+	registry.factories[t] = fa
+	return fa
+}
+
+// For returns the factory registered for T. It panics if there is none.
+func For[T any]() *Factory[T] {
+	t := reflect.TypeFor[T]()
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	fa, ok := registry.factories[t]
+	if !ok {
+		panic(fmt.Sprintf("factory: no factory defined for %s", t))
+	}
+	return fa.(*Factory[T])
+}
+
+// Persist sets how Create saves a value, typically by calling the model's
+// Insert method and storing the new ID in v. It returns fa for chaining.
+func (fa *Factory[T]) Persist(fn func(ctx context.Context, v *T) error) *Factory[T] {
+	fa.mu.Lock()
+	defer fa.mu.Unlock()
+	fa.persist = fn
+	return fa
+}
+
+// BelongsTo declares that values of T reference a P, such as a Token that
+// belongs to a User. Unless set reports that an override already supplied
+// the parent, Make builds a parent in memory and Create creates one in the
+// database, using P's factory, and link copies what the child needs from it.
+func BelongsTo[T, P any](child *Factory[T], link func(child *T, parent P), set func(child *T) bool) {
+	child.mu.Lock()
+	defer child.mu.Unlock()
+	child.parents = append(child.parents, func(ctx context.Context, v *T, create bool) error {
+		if set != nil && set(v) {
+			return nil
+		}
+		parents := For[P]()
+		var parent P
+		if create {
+			var err error
+			if parent, err = parents.Create(ctx); err != nil {
+				return err
+			}
+		} else {
+			parent = parents.Make()
+		}
+		link(v, parent)
+		return nil
+	})
+}
+
+// build generates a value with the defaults, then applies the overrides.
+func (fa *Factory[T]) build(overrides []func(*T)) (T, []func(context.Context, *T, bool) error) {
+	fa.mu.Lock()
+	fa.seq++
+	v := fa.defaults(fa.faker, fa.seq)
+	parents := fa.parents
+	fa.mu.Unlock()
+
+	for _, override := range overrides {
+		override(&v)
+	}
+	return v, parents
+}
+
+// Make returns a value built in memory.
+func (fa *Factory[T]) Make(overrides ...func(*T)) T {
+	v, parents := fa.build(overrides)
+	for _, parent := range parents {
+		_ = parent(context.Background(), &v, false) // making in memory cannot fail
+	}
+	return v
+}
+
+// MakeMany returns n values built in memory.
+func (fa *Factory[T]) MakeMany(n int, overrides ...func(*T)) []T {
+	vs := make([]T, n)
+	for i := range vs {
+		vs[i] = fa.Make(overrides...)
+	}
+	return vs
+}
+
+// Create builds a value, creates its parents and persists it.
+func (fa *Factory[T]) Create(ctx context.Context, overrides ...func(*T)) (T, error) {
+	fa.mu.Lock()
+	persist := fa.persist
+	fa.mu.Unlock()
+	if persist == nil {
+		var zero T
+		return zero, errors.New("factory: Create needs Persist to be set for " + reflect.TypeFor[T]().String())
+	}
+
+	v, parents := fa.build(overrides)
+	for _, parent := range parents {
+		if err := parent(ctx, &v, true); err != nil {
+			return v, err
+		}
+	}
+	err := persist(ctx, &v)
+	return v, err
+}
+
+// CreateMany creates n values, stopping at the first error.
+func (fa *Factory[T]) CreateMany(ctx context.Context, n int, overrides ...func(*T)) ([]T, error) {
+	vs := make([]T, 0, n)
+	for range n {
+		v, err := fa.Create(ctx, overrides...)
+		if err != nil {
+			return vs, err
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+func (fa *Factory[T]) reset(seed uint64) {
+	fa.mu.Lock()
+	defer fa.mu.Unlock()
+	fa.faker = NewFaker(seed)
+	fa.seq = 0
+}
+
+// Reset reseeds every factory and restarts their sequences, so the values
+// built afterwards are the same on every run with the same seed.
+func Reset(seed uint64) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for _, fa := range registry.factories {
+		fa.reset(seed)
+	}
+}
+
+// Make builds a value with the factory registered for T.
+func Make[T any](overrides ...func(*T)) T {
+	return For[T]().Make(overrides...)
+}
+
+// Create builds and persists a value with the factory registered for T.
+func Create[T any](ctx context.Context, overrides ...func(*T)) (T, error) {
+	return For[T]().Create(ctx, overrides...)
+}
@@ -0,0 +1,184 @@
+package factory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type user struct {
+	ID        int
+	Name      string
+	Email     string
+	CreatedAt time.Time
+}
+
+type token struct {
+	ID     int
+	UserID int
+	Email  string
+	Token  string
+}
+
+type note struct {
+	Body string
+}
+
+// store stands in for a database table.
+type store[T any] struct {
+	rows []T
+	fail error
+}
+
+var (
+	users  store[user]
+	tokens store[token]
+
+	userFactory = Define(func(f *Faker, seq int) user {
+		return user{Name: f.Name(), Email: f.Email(seq), CreatedAt: f.Time()}
+	}).Persist(func(ctx context.Context, u *user) error {
+		if users.fail != nil {
+			return users.fail
+		}
+		u.ID = len(users.rows) + 1
+		users.rows = append(users.rows, *u)
+		return nil
+	})
+
+	tokenFactory = Define(func(f *Faker, seq int) token {
+		return token{Token: f.Hex(16)}
+	}).Persist(func(ctx context.Context, t *token) error {
+		t.ID = len(tokens.rows) + 1
+		tokens.rows = append(tokens.rows, *t)
+		return nil
+	})
+
+	noteFactory = Define(func(f *Faker, seq int) note {
+		return note{Body: f.Sentence(4)}
+	})
+)
+
+func init() {
+	BelongsTo(tokenFactory, func(t *token, u user) {
+		t.UserID = u.ID
+		t.Email = u.Email
+	}, func(t *token) bool { return t.UserID != 0 })
+}
+
+func resetAll() {
+	Reset(DefaultSeed)
+	users = store[user]{}
+	tokens = store[token]{}
+}
+
+func TestFactory_Make(t *testing.T) {
+	resetAll()
+
+	first := Make[user]()
+	second := userFactory.Make(func(u *user) { u.Name = "Override" })
+
+	if first.Name == "" || first.CreatedAt.IsZero() {
+		t.Errorf("Make() = %+v, want defaults filled in", first)
+	}
+	if second.Name != "Override" {
+		t.Errorf("Make() Name = %q, want the override", second.Name)
+	}
+	if !strings.HasSuffix(first.Email, "1@example.com") || !strings.HasSuffix(second.Email, "2@example.com") {
+		t.Errorf("Make() emails = %q, %q, want sequence numbers 1 and 2", first.Email, second.Email)
+	}
+	if len(users.rows) != 0 {
+		t.Error("Make() persisted a value")
+	}
+
+	if got := noteFactory.MakeMany(3); len(got) != 3 || !strings.HasSuffix(got[0].Body, ".") {
+		t.Errorf("MakeMany() = %v, want three sentences", got)
+	}
+}
+
+func TestFactory_deterministic(t *testing.T) {
+	resetAll()
+	a := userFactory.MakeMany(5)
+	resetAll()
+	b := userFactory.MakeMany(5)
+
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("after Reset value %d = %+v, want %+v", i, b[i], a[i])
+		}
+	}
+
+	Reset(42)
+	if c := userFactory.Make(); c == a[0] {
+		t.Error("a different seed produced the same value")
+	}
+}
+
+func TestFactory_Create(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("persists", func(ts *testing.T) {
+		resetAll()
+		u, err := Create(ctx, func(u *user) { u.Email = "admin@example.com" })
+		if err != nil {
+			ts.Fatalf("Create() unexpected error: %v", err)
+		}
+		if u.ID != 1 || len(users.rows) != 1 || users.rows[0].Email != "admin@example.com" {
+			ts.Errorf("Create() = %+v, stored %+v", u, users.rows)
+		}
+	})
+
+	t.Run("creates the parent", func(ts *testing.T) {
+		resetAll()
+		tok, err := tokenFactory.Create(ctx)
+		if err != nil {
+			ts.Fatalf("Create() unexpected error: %v", err)
+		}
+		if len(users.rows) != 1 || tok.UserID != users.rows[0].ID || tok.Email != users.rows[0].Email {
+			ts.Errorf("Create() token = %+v, users %+v, want a token for a new user", tok, users.rows)
+		}
+	})
+
+	t.Run("uses a given parent", func(ts *testing.T) {
+		resetAll()
+		owner, _ := userFactory.Create(ctx)
+		toks, err := tokenFactory.CreateMany(ctx, 2, func(t *token) { t.UserID = owner.ID })
+		if err != nil {
+			ts.Fatalf("CreateMany() unexpected error: %v", err)
+		}
+		if len(users.rows) != 1 || toks[0].UserID != owner.ID || toks[1].UserID != owner.ID {
+			ts.Errorf("CreateMany() = %+v, users %+v, want both tokens for the given user", toks, users.rows)
+		}
+	})
+
+	t.Run("make builds the parent in memory", func(ts *testing.T) {
+		resetAll()
+		if tok := tokenFactory.Make(); tok.Email == "" {
+			ts.Errorf("Make() = %+v, want the parent's email linked", tok)
+		}
+		if len(users.rows) != 0 {
+			ts.Error("Make() persisted the parent")
+		}
+	})
+
+	t.Run("errors", func(ts *testing.T) {
+		resetAll()
+		users.fail = errors.New("insert failed")
+		if _, err := tokenFactory.Create(ctx); err == nil {
+			ts.Error("Create() unexpected success when the parent fails")
+		}
+		if _, err := noteFactory.Create(ctx); err == nil {
+			ts.Error("Create() unexpected success without Persist")
+		}
+	})
+}
+
+func TestDefine_duplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Define() did not panic for a type that already has a factory")
+		}
+	}()
+	Define(func(f *Faker, seq int) note { return note{} })
+}
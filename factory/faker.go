@@ -0,0 +1,106 @@
+package factory
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+var (
+	firstNames = []string{
+		"Ada", "Alan", "Barbara", "Claude", "Donald", "Edsger", "Frances", "Grace",
+		"Hedy", "Ivan", "Jean", "Ken", "Linus", "Margaret", "Niklaus", "Radia",
+		"Rob", "Shafi", "Tim", "Vint",
+	}
+	lastNames = []string{
+		"Allen", "Backus", "Cerf", "Dijkstra", "Engelbart", "Hamilton", "Hopper",
+		"Kay", "Knuth", "Lamarr", "Liskov", "Lovelace", "McCarthy", "Perlman",
+		"Pike", "Ritchie", "Shannon", "Thompson", "Turing", "Wirth",
+	}
+	words = []string{
+		"alpha", "bridge", "cobalt", "delta", "ember", "falcon", "garden", "harbor",
+		"island", "juniper", "kernel", "lantern", "meadow", "nimbus", "orbit",
+		"pepper", "quartz", "river", "summit", "timber", "umber", "valley",
+		"willow", "zephyr",
+	}
+)
+
+// epoch anchors generated times, so they do not depend on when a test runs.
+var epoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Faker generates plausible field values from a seeded source, so the same
+// seed always yields the same values.
+type Faker struct {
+	rng *rand.Rand
+}
+
+// NewFaker returns a Faker seeded with seed.
+func NewFaker(seed uint64) *Faker {
+	return &Faker{rng: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// FirstName returns a given name.
+func (f *Faker) FirstName() string {
+	return pick(f, firstNames)
+}
+
+// LastName returns a family name.
+func (f *Faker) LastName() string {
+	return pick(f, lastNames)
+}
+
+// Name returns a full name.
+func (f *Faker) Name() string {
+	return f.FirstName() + " " + f.LastName()
+}
+
+// Email returns an address at example.com. The sequence number n keeps
+// addresses unique within a factory; pass the seq given to the defaults.
+func (f *Faker) Email(n int) string {
+	return fmt.Sprintf("%s.%s%d@example.com",
+		strings.ToLower(f.FirstName()), strings.ToLower(f.LastName()), n)
+}
+
+// Word returns a single lower-case word.
+func (f *Faker) Word() string {
+	return pick(f, words)
+}
+
+// Sentence returns n words, capitalised and ending in a full stop.
+func (f *Faker) Sentence(n int) string {
+	s := make([]string, n)
+	for i := range s {
+		s[i] = f.Word()
+	}
+	sentence := strings.Join(s, " ") + "."
+	return strings.ToUpper(sentence[:1]) + sentence[1:]
+}
+
+// IntBetween returns an int in [min, max].
+func (f *Faker) IntBetween(min, max int) int {
+	return min + f.rng.IntN(max-min+1)
+}
+
+// Bool returns true or false with equal probability.
+func (f *Faker) Bool() bool {
+	return f.rng.IntN(2) == 1
+}
+
+// Time returns a time within the year starting 1 January 2024 UTC.
+func (f *Faker) Time() time.Time {
+	return epoch.Add(time.Duration(f.rng.Int64N(int64(365 * 24 * time.Hour)))).Truncate(time.Second)
+}
+
+// Hex returns a random hexadecimal string of n bytes, for tokens and keys.
+func (f *Faker) Hex(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(f.rng.UintN(256))
+	}
+	return fmt.Sprintf("%x", b)
+}
+
+func pick(f *Faker, list []string) string {
+	return list[f.rng.IntN(len(list))]
+}
@@ -3,40 +3,49 @@ module github.com/polyglotdev/celeritasproject
 go 1.24.0
 
 require (
-  github.com/CloudyKit/jet/v6 v6.2.0
-  github.com/alexedwards/scs/mysqlstore v0.0.0-20240316134038-7e11d57e8885
-  github.com/alexedwards/scs/postgresstore v0.0.0-20240316134038-7e11d57e8885
-  github.com/alexedwards/scs/redisstore v0.0.0-20240316134038-7e11d57e8885
-  github.com/alexedwards/scs/v2 v2.8.0
-  github.com/asaskevich/govalidator v0.0.0-20230301143203-a9d515a09cc2
-  github.com/fatih/color v1.18.0
-  github.com/gertd/go-pluralize v0.2.1
-  github.com/go-chi/chi/v5 v5.1.0
-  github.com/go-sql-driver/mysql v1.7.1
-  github.com/golang-migrate/migrate/v4 v4.18.1
-  github.com/gomodule/redigo v1.9.2
-  github.com/iancoleman/strcase v0.3.0
-  github.com/jackc/pgconn v1.14.3
-  github.com/jackc/pgx/v4 v4.18.3
-  github.com/joho/godotenv v1.5.1
-  github.com/justinas/nosurf v1.1.1
+	github.com/CloudyKit/jet/v6 v6.2.0
+	github.com/alexedwards/scs/mysqlstore v0.0.0-20240316134038-7e11d57e8885
+	github.com/alexedwards/scs/postgresstore v0.0.0-20240316134038-7e11d57e8885
+	github.com/alexedwards/scs/redisstore v0.0.0-20240316134038-7e11d57e8885
+	github.com/alexedwards/scs/sqlite3store v0.0.0-20251002162104-209de6e426de
+	github.com/alexedwards/scs/v2 v2.8.0
+	github.com/asaskevich/govalidator v0.0.0-20230301143203-a9d515a09cc2
+	github.com/fatih/color v1.18.0
+	github.com/gertd/go-pluralize v0.2.1
+	github.com/go-chi/chi/v5 v5.1.0
+	github.com/go-sql-driver/mysql v1.7.1
+	github.com/golang-migrate/migrate/v4 v4.18.1
+	github.com/gomodule/redigo v1.9.2
+	github.com/iancoleman/strcase v0.3.0
+	github.com/jackc/pgconn v1.14.3
+	github.com/jackc/pgx/v4 v4.18.3
+	github.com/joho/godotenv v1.5.1
+	github.com/justinas/nosurf v1.1.1
+	modernc.org/sqlite v1.34.5
 )
 
 require (
-  github.com/CloudyKit/fastprinter v0.0.0-20200109182630-33d98a066a53 // indirect
-  github.com/hashicorp/errwrap v1.1.0 // indirect
-  github.com/hashicorp/go-multierror v1.1.1 // indirect
-  github.com/jackc/chunkreader/v2 v2.0.1 // indirect
-  github.com/jackc/pgio v1.0.0 // indirect
-  github.com/jackc/pgpassfile v1.0.0 // indirect
-  github.com/jackc/pgproto3/v2 v2.3.3 // indirect
-  github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
-  github.com/jackc/pgtype v1.14.0 // indirect
-  github.com/lib/pq v1.10.9 // indirect
-  github.com/mattn/go-colorable v0.1.13 // indirect
-  github.com/mattn/go-isatty v0.0.20 // indirect
-  go.uber.org/atomic v1.7.0 // indirect
-  golang.org/x/crypto v0.35.0 // indirect
-  golang.org/x/sys v0.30.0 // indirect
-  golang.org/x/text v0.22.0 // indirect
+	github.com/CloudyKit/fastprinter v0.0.0-20200109182630-33d98a066a53 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/hashicorp/errwrap v1.1.0 // indirect
+	github.com/hashicorp/go-multierror v1.1.1 // indirect
+	github.com/jackc/chunkreader/v2 v2.0.1 // indirect
+	github.com/jackc/pgio v1.0.0 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgproto3/v2 v2.3.3 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
+	github.com/jackc/pgtype v1.14.0 // indirect
+	github.com/lib/pq v1.10.9 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/ncruces/go-strftime v0.1.9 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	go.uber.org/atomic v1.7.0 // indirect
+	golang.org/x/crypto v0.35.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+	modernc.org/libc v1.55.3 // indirect
+	modernc.org/mathutil v1.6.0 // indirect
+	modernc.org/memory v1.8.0 // indirect
 )
@@ -0,0 +1,252 @@
+package celeritas
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// MigrationFunc is the up or down half of a Go migration. It runs in a
+// transaction that is rolled back if it returns an error.
+type MigrationFunc func(ctx context.Context, tx *sql.Tx) error
+
+// goMigration is a migration registered with RegisterMigration.
+type goMigration struct {
+	name     string
+	up, down MigrationFunc
+}
+
+var migrationRegistry = struct {
+	mu         sync.Mutex
+	migrations map[uint]goMigration
+}{migrations: make(map[uint]goMigration)}
+
+// RegisterMigration adds a Go migration with the given version, for changes
+// such as data backfills that are awkward to write in SQL. Go migrations run
+// in version order with the SQL files in the migrations folder and are
+// recorded in the same version table, so a version must not be used by both.
+// A nil down records the rollback without changing anything.
+//
+// It is meant to be called from the init function of the files
+// `celeritas make migration <name> --go` generates in the migrations package,
+// and panics if version is registered twice. The application must import the
+// migrations package for its Go migrations to run.
+func RegisterMigration(version uint, name string, up, down MigrationFunc) {
+	migrationRegistry.mu.Lock()
+	defer migrationRegistry.mu.Unlock()
+	if up == nil {
+		panic("celeritas: RegisterMigration up is nil")
+	}
+	if _, dup := migrationRegistry.migrations[version]; dup {
+		panic("celeritas: RegisterMigration called twice for version " + strconv.FormatUint(uint64(version), 10))
+	}
+	migrationRegistry.migrations[version] = goMigration{name: name, up: up, down: down}
+}
+
+// goMigrationMarker starts the body the migration source returns for a Go
+// migration. golang-migrate hands the body to the database driver, which
+// recognises the marker and calls the Go function instead of running SQL.
+const goMigrationMarker = "-- celeritas:go-migration "
+
+// migrationSource is a golang-migrate source driver over the migration files
+// in fsys and the registered Go migrations.
+type migrationSource struct {
+	fsys       fs.FS
+	migrations *source.Migrations
+	goFuncs    map[uint]goMigration
+}
+
+// newMigrationSource lists the migration files in fsys and merges in the
+// registered Go migrations. It errors if two migrations share a version.
+func newMigrationSource(fsys fs.FS) (*migrationSource, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &migrationSource{fsys: fsys, migrations: source.NewMigrations(), goFuncs: make(map[uint]goMigration)}
+	files := make(map[uint]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		m, err := source.DefaultParse(entry.Name())
+		if err != nil {
+			continue // not a migration
+		}
+		if !s.migrations.Append(m) {
+			return nil, fmt.Errorf("duplicate migration file %s", entry.Name())
+		}
+		files[m.Version] = entry.Name()
+	}
+
+	migrationRegistry.mu.Lock()
+	defer migrationRegistry.mu.Unlock()
+	for version, gm := range migrationRegistry.migrations {
+		if file, ok := files[version]; ok {
+			return nil, fmt.Errorf("migration version %d is used by both %s and the Go migration %s", version, file, gm.name)
+		}
+		s.goFuncs[version] = gm
+		s.migrations.Append(&source.Migration{Version: version, Identifier: gm.name, Direction: source.Up})
+		if gm.down != nil {
+			s.migrations.Append(&source.Migration{Version: version, Identifier: gm.name, Direction: source.Down})
+		}
+	}
+	return s, nil
+}
+
+// Open is part of the source.Driver interface. The source is created with
+// newMigrationSource instead.
+func (s *migrationSource) Open(url string) (source.Driver, error) {
+	return nil, errors.New("the celeritas migration source cannot be opened by URL")
+}
+
+func (s *migrationSource) Close() error {
+	if c, ok := s.fsys.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (s *migrationSource) First() (uint, error) {
+	if version, ok := s.migrations.First(); ok {
+		return version, nil
+	}
+	return 0, s.notExist("first", 0)
+}
+
+func (s *migrationSource) Prev(version uint) (uint, error) {
+	if prev, ok := s.migrations.Prev(version); ok {
+		return prev, nil
+	}
+	return 0, s.notExist("prev for version", version)
+}
+
+func (s *migrationSource) Next(version uint) (uint, error) {
+	if next, ok := s.migrations.Next(version); ok {
+		return next, nil
+	}
+	return 0, s.notExist("next for version", version)
+}
+
+func (s *migrationSource) ReadUp(version uint) (io.ReadCloser, string, error) {
+	if m, ok := s.migrations.Up(version); ok {
+		return s.read(m)
+	}
+	return nil, "", s.notExist("read up for version", version)
+}
+
+func (s *migrationSource) ReadDown(version uint) (io.ReadCloser, string, error) {
+	if m, ok := s.migrations.Down(version); ok {
+		return s.read(m)
+	}
+	return nil, "", s.notExist("read down for version", version)
+}
+
+// read returns the body of m: the file's contents, or the marker for a Go
+// migration.
+func (s *migrationSource) read(m *source.Migration) (io.ReadCloser, string, error) {
+	if _, ok := s.goFuncs[m.Version]; ok {
+		marker := fmt.Sprintf("%s%d %s\n", goMigrationMarker, m.Version, m.Direction)
+		return io.NopCloser(strings.NewReader(marker)), m.Identifier, nil
+	}
+	f, err := s.fsys.Open(m.Raw)
+	if err != nil {
+		return nil, "", err
+	}
+	return f, m.Identifier, nil
+}
+
+// notExist returns the error golang-migrate expects when there is no
+// migration in the asked direction.
+func (s *migrationSource) notExist(op string, version uint) error {
+	if version != 0 {
+		op += " " + strconv.FormatUint(uint64(version), 10)
+	}
+	return &fs.PathError{Op: op, Path: "migrations", Err: fs.ErrNotExist}
+}
+
+// upMigrations returns the up migrations in version order.
+func (s *migrationSource) upMigrations() []*source.Migration {
+	var ups []*source.Migration
+	version, ok := s.migrations.First()
+	for ok {
+		if m, found := s.migrations.Up(version); found {
+			ups = append(ups, m)
+		}
+		version, ok = s.migrations.Next(version)
+	}
+	return ups
+}
+
+// migrationDriver wraps the golang-migrate database driver so that Go
+// migrations run on the application's connection pool. SQL migrations, the
+// version table and locking are left to the wrapped driver.
+type migrationDriver struct {
+	database.Driver
+	db      Database
+	goFuncs map[uint]goMigration
+}
+
+// Run runs a Go migration when the body is a marker from migrationSource and
+// passes anything else to the wrapped driver. A body of only comments, such
+// as a freshly generated migration, runs nothing; MySQL would reject it as an
+// empty query and leave the schema dirty.
+func (d *migrationDriver) Run(migration io.Reader) error {
+	body, err := io.ReadAll(migration)
+	if err != nil {
+		return err
+	}
+
+	rest, ok := bytes.CutPrefix(body, []byte(goMigrationMarker))
+	if !ok {
+		if blankSQL(string(body)) {
+			return nil
+		}
+		return d.Driver.Run(bytes.NewReader(body))
+	}
+
+	fields := strings.Fields(string(rest))
+	if len(fields) != 2 {
+		return fmt.Errorf("malformed Go migration marker %q", body)
+	}
+	version, err := strconv.ParseUint(fields[0], 10, 0)
+	if err != nil {
+		return fmt.Errorf("malformed Go migration marker %q", body)
+	}
+	gm, ok := d.goFuncs[uint(version)]
+	if !ok {
+		return fmt.Errorf("no Go migration registered for version %d", version)
+	}
+
+	fn := gm.up
+	if fields[1] == string(source.Down) {
+		fn = gm.down
+	}
+	if d.db.Pool == nil {
+		return fmt.Errorf("Go migration %d_%s needs a database connection", version, gm.name)
+	}
+
+	ctx := context.Background()
+	if err := d.db.WithTx(ctx, nil, func(tx *sql.Tx) error { return fn(ctx, tx) }); err != nil {
+		return fmt.Errorf("Go migration %d_%s %s: %w", version, gm.name, fields[1], err)
+	}
+	return nil
+}
+
+// hasGoMigrations reports whether any Go migrations are registered.
+func hasGoMigrations() bool {
+	migrationRegistry.mu.Lock()
+	defer migrationRegistry.mu.Unlock()
+	return len(migrationRegistry.migrations) > 0
+}
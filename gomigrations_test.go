@@ -0,0 +1,229 @@
+package celeritas
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/database"
+)
+
+// withMigrations replaces the Go migration registry for the duration of the
+// test.
+func withMigrations(t *testing.T) {
+	t.Helper()
+	migrationRegistry.mu.Lock()
+	saved := migrationRegistry.migrations
+	migrationRegistry.migrations = make(map[uint]goMigration)
+	migrationRegistry.mu.Unlock()
+	t.Cleanup(func() {
+		migrationRegistry.mu.Lock()
+		migrationRegistry.migrations = saved
+		migrationRegistry.mu.Unlock()
+	})
+}
+
+// newGoMigrationApp returns an application on a SQLite database with SQL
+// migrations 1, 3 and 4, and the URL to migrate its database with.
+func newGoMigrationApp(t *testing.T) (*Celeritas, string) {
+	t.Helper()
+	c, _ := newSQLiteApp(t, nil)
+	writeMigrations(t, c.RootPath, map[string]string{
+		"1_create_items.up.sql":   "create table items (name varchar(255), slug varchar(255));",
+		"1_create_items.down.sql": "drop table items;",
+		"3_index_slugs.up.sql":    "create unique index items_slug on items (slug);",
+		"3_index_slugs.down.sql":  "drop index items_slug;",
+		"4_create_notes.up.sql":   "create table notes (id integer);",
+		"4_create_notes.down.sql": "drop table notes;",
+		"README.md":               "not a migration",
+	})
+	return c, c.MigrateURL()
+}
+
+func TestCeleritas_MigrateUp_goMigrations(t *testing.T) {
+	withMigrations(t)
+	c, dsn := newGoMigrationApp(t)
+
+	// the backfill runs between creating the table and indexing the slugs,
+	// which would fail without it
+	RegisterMigration(2, "backfill_slugs", func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "insert into items (name) values ('Blue Widget'), ('Red Widget')"); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "update items set slug = lower(replace(name, ' ', '-'))")
+		return err
+	}, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "update items set slug = null")
+		return err
+	})
+
+	if err := c.Steps(3, dsn); err != nil {
+		t.Fatalf("Steps(3) unexpected error: %v", err)
+	}
+	var slugs string
+	c.DB.Pool.QueryRow("select group_concat(slug, ',') from (select slug from items order by slug)").Scan(&slugs)
+	if slugs != "blue-widget,red-widget" {
+		t.Errorf("slugs after up = %q, want the backfilled slugs", slugs)
+	}
+
+	statuses, err := c.MigrateStatus(dsn)
+	if err != nil {
+		t.Fatalf("MigrateStatus() unexpected error: %v", err)
+	}
+	want := []MigrationStatus{
+		{Version: 1, Name: "create_items", Applied: true},
+		{Version: 2, Name: "backfill_slugs", Applied: true},
+		{Version: 3, Name: "index_slugs", Applied: true},
+		{Version: 4, Name: "create_notes"},
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("MigrateStatus() = %+v, want %+v", statuses, want)
+	}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Errorf("MigrateStatus()[%d] = %+v, want %+v", i, statuses[i], want[i])
+		}
+	}
+
+	// rolling back past the Go migration runs its down function
+	if err := c.MigrateGoto(1, dsn); err != nil {
+		t.Fatalf("MigrateGoto(1) unexpected error: %v", err)
+	}
+	var missing int
+	c.DB.Pool.QueryRow("select count(*) from items where slug is null").Scan(&missing)
+	if missing != 2 {
+		t.Errorf("rows without a slug after down = %d, want 2", missing)
+	}
+}
+
+func TestCeleritas_MigrateUp_goMigrationFails(t *testing.T) {
+	withMigrations(t)
+	c, dsn := newGoMigrationApp(t)
+
+	RegisterMigration(2, "backfill_slugs", func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "insert into items (name) values ('Blue Widget')"); err != nil {
+			return err
+		}
+		return errors.New("backfill failed")
+	}, nil)
+
+	err := c.MigrateUp(dsn)
+	if err == nil || !strings.Contains(err.Error(), "backfill failed") {
+		t.Fatalf("MigrateUp() error = %v, want the Go migration's error", err)
+	}
+
+	version, dirty, _ := c.MigrateVersion(dsn)
+	if version != 2 || !dirty {
+		t.Errorf("MigrateVersion() = %d, %v, want 2, true", version, dirty)
+	}
+	var rows int
+	c.DB.Pool.QueryRow("select count(*) from items").Scan(&rows)
+	if rows != 0 {
+		t.Errorf("items after a failed Go migration = %d, want the insert rolled back", rows)
+	}
+}
+
+func TestCeleritas_newMigrate_goMigrationErrors(t *testing.T) {
+	up := func(ctx context.Context, tx *sql.Tx) error { return nil }
+
+	t.Run("version used twice", func(ts *testing.T) {
+		withMigrations(ts)
+		c, dsn := newGoMigrationApp(ts)
+		RegisterMigration(3, "backfill", up, nil)
+		if err := c.MigrateUp(dsn); err == nil || !strings.Contains(err.Error(), "3_index_slugs") {
+			ts.Errorf("MigrateUp() error = %v, want the clash with 3_index_slugs reported", err)
+		}
+	})
+
+	t.Run("no connection", func(ts *testing.T) {
+		withMigrations(ts)
+		c, dsn := newGoMigrationApp(ts)
+		c.DB.Pool = nil
+		RegisterMigration(2, "backfill", up, nil)
+		if err := c.MigrateUp(dsn); err == nil || !strings.Contains(err.Error(), "database connection") {
+			ts.Errorf("MigrateUp() error = %v, want a missing connection error", err)
+		}
+	})
+
+	t.Run("registered twice", func(ts *testing.T) {
+		withMigrations(ts)
+		RegisterMigration(2, "backfill", up, nil)
+		defer func() {
+			if recover() == nil {
+				ts.Error("RegisterMigration() did not panic for a version that is already registered")
+			}
+		}()
+		RegisterMigration(2, "backfill_again", up, nil)
+	})
+}
+
+// recordingDriver is a database driver that records the migrations it runs.
+type recordingDriver struct {
+	database.Driver
+	ran []string
+}
+
+func (d *recordingDriver) Run(migration io.Reader) error {
+	body, err := io.ReadAll(migration)
+	d.ran = append(d.ran, string(body))
+	return err
+}
+
+func TestMigrationDriver_Run_blankMigration(t *testing.T) {
+	templates, err := filepath.Glob(filepath.Join("cmd", "cli", "templates", "migrations", "migration.*.sql"))
+	if err != nil || len(templates) == 0 {
+		t.Fatalf("no migration templates found: %v", err)
+	}
+
+	for _, template := range templates {
+		t.Run(filepath.Base(template), func(ts *testing.T) {
+			body, err := os.ReadFile(template)
+			if err != nil {
+				ts.Fatal(err)
+			}
+			recorder := &recordingDriver{}
+			d := &migrationDriver{Driver: recorder}
+			if err := d.Run(bytes.NewReader(body)); err != nil {
+				ts.Fatalf("Run() unexpected error: %v", err)
+			}
+			if len(recorder.ran) != 0 {
+				ts.Errorf("Run() passed the unedited template to the database: %q", recorder.ran)
+			}
+		})
+	}
+
+	t.Run("migrate up", func(ts *testing.T) {
+		c, _ := newSQLiteApp(ts, nil)
+		dir := filepath.Join(c.RootPath, "migrations")
+		if err := os.Mkdir(dir, 0755); err != nil {
+			ts.Fatal(err)
+		}
+		copyMigration(ts, dir, "migration.sqlite.up.sql", "1_create_widgets.sqlite.up.sql")
+		copyMigration(ts, dir, "migration.sqlite.down.sql", "1_create_widgets.sqlite.down.sql")
+
+		if err := c.MigrateUp(c.MigrateURL()); err != nil {
+			ts.Fatalf("MigrateUp() unexpected error: %v", err)
+		}
+		if version, dirty, _ := c.MigrateVersion(c.MigrateURL()); version != 1 || dirty {
+			ts.Errorf("MigrateVersion() = %d, %v, want 1, false", version, dirty)
+		}
+	})
+
+	t.Run("statement", func(ts *testing.T) {
+		recorder := &recordingDriver{}
+		d := &migrationDriver{Driver: recorder}
+		sql := "-- add a\ncreate table a (id integer);\n"
+		if err := d.Run(strings.NewReader(sql)); err != nil {
+			ts.Fatalf("Run() unexpected error: %v", err)
+		}
+		if len(recorder.ran) != 1 || recorder.ran[0] != sql {
+			ts.Errorf("Run() ran %q, want %q", recorder.ran, sql)
+		}
+	})
+}
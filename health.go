@@ -0,0 +1,229 @@
+package celeritas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// HealthConfig holds the settings for the liveness and readiness endpoints.
+type HealthConfig struct {
+	LivePath  string        `env:"HEALTH_PATH" default:"/healthz"`
+	ReadyPath string        `env:"READY_PATH" default:"/readyz"`
+	Timeout   time.Duration `env:"HEALTH_CHECK_TIMEOUT" default:"2s"` // per check, unless the check sets its own
+}
+
+// validate checks the health endpoint settings.
+func (h HealthConfig) validate() []string {
+	var problems []string
+	if !strings.HasPrefix(h.LivePath, "/") {
+		problems = append(problems, fmt.Sprintf("HEALTH_PATH: must start with / (got %q)", h.LivePath))
+	}
+	if !strings.HasPrefix(h.ReadyPath, "/") {
+		problems = append(problems, fmt.Sprintf("READY_PATH: must start with / (got %q)", h.ReadyPath))
+	}
+	if h.Timeout <= 0 {
+		problems = append(problems, "HEALTH_CHECK_TIMEOUT: must be greater than zero")
+	}
+	return problems
+}
+
+// HealthCheck is a dependency probed by the readiness endpoint.
+type HealthCheck struct {
+	// Name identifies the check in the JSON report.
+	Name string
+
+	// Check returns an error when the dependency is unavailable. It must
+	// return promptly once ctx is done.
+	Check func(ctx context.Context) error
+
+	// Timeout bounds a single run of Check. Zero uses HEALTH_CHECK_TIMEOUT.
+	Timeout time.Duration
+
+	// Critical checks make the application not ready when they fail;
+	// failures of other checks are reported but the status stays 200.
+	Critical bool
+}
+
+// healthChecks holds the checks registered with AddHealthCheck.
+type healthChecks struct {
+	mu     sync.Mutex
+	checks []HealthCheck
+}
+
+// AddHealthCheck registers a check run by the readiness endpoint. The
+// database and Redis pools configured by Celeritas are registered as
+// critical checks automatically.
+func (c *Celeritas) AddHealthCheck(check HealthCheck) {
+	c.health.mu.Lock()
+	defer c.health.mu.Unlock()
+	c.health.checks = append(c.health.checks, check)
+}
+
+// setupHealth registers the built-in checks. The endpoints are served by
+// Handler.
+func (c *Celeritas) setupHealth(redisPool *redis.Pool) {
+	if c.DB.Pool != nil {
+		pool := c.DB.Pool
+		c.AddHealthCheck(HealthCheck{
+			Name:     "database",
+			Check:    pool.PingContext,
+			Critical: true,
+		})
+	}
+	if c.DB.replicas != nil {
+		// the primary serves reads when replicas are down, so they only
+		// degrade the report
+		for _, r := range c.DB.replicas.replicas {
+			c.AddHealthCheck(HealthCheck{
+				Name:  "database replica " + r.name,
+				Check: r.db.PingContext,
+			})
+		}
+	}
+	if redisPool != nil {
+		c.AddHealthCheck(HealthCheck{
+			Name: "redis",
+			Check: func(ctx context.Context) error {
+				conn, err := redisPool.GetContext(ctx)
+				if err != nil {
+					return err
+				}
+				defer conn.Close()
+				_, err = redis.DoContext(conn, ctx, "PING")
+				return err
+			},
+			Critical: true,
+		})
+	}
+}
+
+// Health report statuses.
+const (
+	healthOK           = "ok"
+	healthDegraded     = "degraded"
+	healthUnavailable  = "unavailable"
+	healthShuttingDown = "shutting_down"
+	healthFail         = "fail"
+)
+
+// HealthReport is the JSON body written by the health endpoints.
+type HealthReport struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+// HealthCheckResult is the outcome of one check in a HealthReport.
+type HealthCheckResult struct {
+	Status   string  `json:"status"`
+	Critical bool    `json:"critical"`
+	Error    string  `json:"error,omitempty"`
+	Duration float64 `json:"duration_ms"`
+}
+
+// Liveness reports that the process is up and serving requests. It does not
+// probe dependencies, so an outage elsewhere never gets the process
+// restarted.
+func (c *Celeritas) Liveness(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, HealthReport{Status: healthOK})
+}
+
+// Readiness runs every registered check concurrently and reports whether the
+// application should receive traffic. It responds 503 when a critical check
+// fails or once a graceful shutdown has begun, so load balancers stop routing
+// to the instance while in-flight requests drain.
+func (c *Celeritas) Readiness(w http.ResponseWriter, r *http.Request) {
+	if c.ShuttingDown() {
+		writeHealth(w, http.StatusServiceUnavailable, HealthReport{Status: healthShuttingDown})
+		return
+	}
+
+	report := c.checkHealth(r.Context())
+	status := http.StatusOK
+	if report.Status == healthUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+	writeHealth(w, status, report)
+}
+
+// checkHealth runs the registered checks, each bounded by its own timeout.
+func (c *Celeritas) checkHealth(ctx context.Context) HealthReport {
+	c.health.mu.Lock()
+	checks := append([]HealthCheck(nil), c.health.checks...)
+	c.health.mu.Unlock()
+
+	report := HealthReport{Status: healthOK, Checks: make(map[string]HealthCheckResult, len(checks))}
+	results := make([]HealthCheckResult, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.runHealthCheck(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	for i, check := range checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+		if result.Status == healthOK {
+			continue
+		}
+		if check.Critical {
+			report.Status = healthUnavailable
+		} else if report.Status == healthOK {
+			report.Status = healthDegraded
+		}
+	}
+	return report
+}
+
+// runHealthCheck runs one check. A check that ignores its context is still
+// reported as failed once the timeout passes.
+func (c *Celeritas) runHealthCheck(ctx context.Context, check HealthCheck) HealthCheckResult {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = c.config.Health.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := HealthCheckResult{
+		Status:   healthOK,
+		Critical: check.Critical,
+		Duration: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = healthFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func writeHealth(w http.ResponseWriter, status int, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,168 @@
+package celeritas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alexedwards/scs/v2/memstore"
+)
+
+func TestCeleritas_Readiness(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name         string
+		checks       []HealthCheck
+		shuttingDown bool
+		wantCode     int
+		wantStatus   string
+		wantChecks   map[string]string
+	}{
+		{
+			name:       "no checks",
+			wantCode:   http.StatusOK,
+			wantStatus: "ok",
+		},
+		{
+			name: "all healthy",
+			checks: []HealthCheck{
+				{Name: "database", Check: ok, Critical: true},
+				{Name: "search", Check: ok},
+			},
+			wantCode:   http.StatusOK,
+			wantStatus: "ok",
+			wantChecks: map[string]string{"database": "ok", "search": "ok"},
+		},
+		{
+			name: "non-critical failure degrades",
+			checks: []HealthCheck{
+				{Name: "database", Check: ok, Critical: true},
+				{Name: "search", Check: down},
+			},
+			wantCode:   http.StatusOK,
+			wantStatus: "degraded",
+			wantChecks: map[string]string{"database": "ok", "search": "fail"},
+		},
+		{
+			name: "critical failure is unavailable",
+			checks: []HealthCheck{
+				{Name: "database", Check: down, Critical: true},
+			},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "unavailable",
+			wantChecks: map[string]string{"database": "fail"},
+		},
+		{
+			name: "check timeout",
+			checks: []HealthCheck{
+				{Name: "slow", Check: hang, Timeout: 10 * time.Millisecond, Critical: true},
+			},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "unavailable",
+			wantChecks: map[string]string{"slow": "fail"},
+		},
+		{
+			name:         "shutting down",
+			checks:       []HealthCheck{{Name: "database", Check: ok, Critical: true}},
+			shuttingDown: true,
+			wantCode:     http.StatusServiceUnavailable,
+			wantStatus:   "shutting_down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			c, err := NewApp(WithRootPath(ts.TempDir()), WithConfig(DefaultConfig()), WithSessionStore(memstore.New()))
+			if err != nil {
+				ts.Fatalf("NewApp() unexpected error: %v", err)
+			}
+			for _, check := range tt.checks {
+				c.AddHealthCheck(check)
+			}
+			c.lifecycle.shuttingDown.Store(tt.shuttingDown)
+
+			w := httptest.NewRecorder()
+			c.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != tt.wantCode {
+				ts.Errorf("GET /readyz status = %d, want %d", w.Code, tt.wantCode)
+			}
+			var report HealthReport
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				ts.Fatalf("GET /readyz body is not JSON: %v", err)
+			}
+			if report.Status != tt.wantStatus {
+				ts.Errorf("GET /readyz status = %q, want %q", report.Status, tt.wantStatus)
+			}
+			for name, want := range tt.wantChecks {
+				if got := report.Checks[name].Status; got != want {
+					ts.Errorf("GET /readyz check %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCeleritas_Liveness(t *testing.T) {
+	c, err := NewApp(WithRootPath(t.TempDir()), WithConfig(DefaultConfig()), WithSessionStore(memstore.New()))
+	if err != nil {
+		t.Fatalf("NewApp() unexpected error: %v", err)
+	}
+	c.AddHealthCheck(HealthCheck{
+		Name:     "database",
+		Check:    func(context.Context) error { return errors.New("down") },
+		Critical: true,
+	})
+
+	w := httptest.NewRecorder()
+	c.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("GET /healthz status = %d, want 200 regardless of dependencies", w.Code)
+	}
+}
+
+func TestCeleritas_Handler_middlewareAfterNew(t *testing.T) {
+	c, err := NewApp(WithRootPath(t.TempDir()), WithConfig(DefaultConfig()), WithSessionStore(memstore.New()))
+	if err != nil {
+		t.Fatalf("NewApp() unexpected error: %v", err)
+	}
+
+	// adding middleware panics once a route is on the router
+	c.Routes.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-App", "yes")
+			next.ServeHTTP(w, r)
+		})
+	})
+	c.Routes.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		path     string
+		wantCode int
+		wantApp  string
+	}{
+		{"/", http.StatusOK, "yes"},
+		{"/healthz", http.StatusOK, ""},
+		{"/readyz", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.wantCode {
+			t.Errorf("GET %s status = %d, want %d", tt.path, w.Code, tt.wantCode)
+		}
+		if got := w.Header().Get("X-App"); got != tt.wantApp {
+			t.Errorf("GET %s X-App = %q, want %q", tt.path, got, tt.wantApp)
+		}
+	}
+}
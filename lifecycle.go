@@ -0,0 +1,105 @@
+package celeritas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Hook is a function run at a point in the application lifecycle. The context
+// passed to shutdown hooks carries the remaining grace period as its deadline.
+type Hook func(ctx context.Context) error
+
+// namedHook pairs a hook with the name used when reporting its errors.
+type namedHook struct {
+	name string
+	fn   Hook
+}
+
+// lifecycle holds the hooks registered with OnStart and OnShutdown along with
+// the state used to coordinate a graceful shutdown.
+type lifecycle struct {
+	mu           sync.Mutex
+	onStart      []namedHook
+	onShutdown   []namedHook
+	stopOnce     sync.Once
+	stop         chan struct{}
+	shuttingDown atomic.Bool
+}
+
+// stopChan returns the channel closed by Stop, creating it on first use.
+func (l *lifecycle) stopChan() chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stop == nil {
+		l.stop = make(chan struct{})
+	}
+	return l.stop
+}
+
+// OnStart registers a hook that runs before the server starts accepting
+// connections. Hooks run in the order they were registered; if one fails,
+// ListenAndServe runs the shutdown hooks and returns its error without
+// starting the server.
+func (c *Celeritas) OnStart(name string, fn Hook) {
+	c.lifecycle.mu.Lock()
+	defer c.lifecycle.mu.Unlock()
+	c.lifecycle.onStart = append(c.lifecycle.onStart, namedHook{name: name, fn: fn})
+}
+
+// OnShutdown registers a hook that runs after the server has stopped accepting
+// connections and drained in-flight requests. Hooks run in the reverse order
+// they were registered, so resources opened first are released last.
+func (c *Celeritas) OnShutdown(name string, fn Hook) {
+	c.lifecycle.mu.Lock()
+	defer c.lifecycle.mu.Unlock()
+	c.lifecycle.onShutdown = append(c.lifecycle.onShutdown, namedHook{name: name, fn: fn})
+}
+
+// Stop asks a running ListenAndServe to shut down gracefully, exactly as if
+// the process had received SIGTERM. It is safe to call more than once.
+func (c *Celeritas) Stop() {
+	ch := c.lifecycle.stopChan()
+	c.lifecycle.stopOnce.Do(func() {
+		close(ch)
+	})
+}
+
+// ShuttingDown reports whether a graceful shutdown has begun.
+func (c *Celeritas) ShuttingDown() bool {
+	return c.lifecycle.shuttingDown.Load()
+}
+
+// runStartHooks runs every OnStart hook in registration order, stopping at
+// the first failure.
+func (c *Celeritas) runStartHooks(ctx context.Context) error {
+	c.lifecycle.mu.Lock()
+	hooks := append([]namedHook(nil), c.lifecycle.onStart...)
+	c.lifecycle.mu.Unlock()
+
+	for _, h := range hooks {
+		if err := h.fn(ctx); err != nil {
+			return fmt.Errorf("start hook %q: %w", h.name, err)
+		}
+	}
+	return nil
+}
+
+// runShutdownHooks runs every OnShutdown hook in reverse registration order.
+// A failing hook does not prevent the remaining hooks from running; all
+// errors are joined and returned together.
+func (c *Celeritas) runShutdownHooks(ctx context.Context) error {
+	c.lifecycle.mu.Lock()
+	hooks := append([]namedHook(nil), c.lifecycle.onShutdown...)
+	c.lifecycle.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown hook %q: %w", hooks[i].name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,205 @@
+package celeritas
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestCeleritas_runShutdownHooks(t *testing.T) {
+	tests := []struct {
+		name      string
+		hooks     []string
+		failing   map[string]bool
+		wantOrder []string
+		wantErrs  []string
+	}{
+		{
+			name:      "reverse registration order",
+			hooks:     []string{"database", "redis", "workers"},
+			wantOrder: []string{"workers", "redis", "database"},
+		},
+		{
+			name:      "failures do not stop remaining hooks",
+			hooks:     []string{"database", "redis", "workers"},
+			failing:   map[string]bool{"database": true, "workers": true},
+			wantOrder: []string{"workers", "redis", "database"},
+			wantErrs:  []string{`shutdown hook "workers"`, `shutdown hook "database"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			c := &Celeritas{}
+			var order []string
+			for _, name := range tt.hooks {
+				c.OnShutdown(name, func(ctx context.Context) error {
+					order = append(order, name)
+					if tt.failing[name] {
+						return errors.New("boom")
+					}
+					return nil
+				})
+			}
+
+			err := c.runShutdownHooks(context.Background())
+
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				ts.Errorf("runShutdownHooks() order = %v, want %v", order, tt.wantOrder)
+			}
+			if len(tt.wantErrs) == 0 && err != nil {
+				ts.Errorf("runShutdownHooks() unexpected error: %v", err)
+			}
+			for _, want := range tt.wantErrs {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					ts.Errorf("runShutdownHooks() error = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCeleritas_runStartHooks(t *testing.T) {
+	c := &Celeritas{}
+	var order []string
+	c.OnStart("first", func(ctx context.Context) error {
+		order = append(order, "first")
+		return nil
+	})
+	c.OnStart("second", func(ctx context.Context) error {
+		order = append(order, "second")
+		return errors.New("boom")
+	})
+	c.OnStart("third", func(ctx context.Context) error {
+		order = append(order, "third")
+		return nil
+	})
+
+	err := c.runStartHooks(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `start hook "second"`) {
+		t.Errorf("runStartHooks() error = %v, want start hook \"second\" failure", err)
+	}
+	if want := []string{"first", "second"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("runStartHooks() order = %v, want %v", order, want)
+	}
+}
+
+func TestCeleritas_ListenAndServe_GracefulShutdown(t *testing.T) {
+	c := &Celeritas{}
+	c.AppName = "test_app"
+	c.config.Port = "0"
+	c.config.ShutdownTimeout = time.Second
+	c.InfoLog = log.New(io.Discard, "", 0)
+	c.ErrorLog = log.New(io.Discard, "", 0)
+	c.Routes = chi.NewRouter()
+
+	var order []string
+	c.OnStart("start", func(ctx context.Context) error {
+		order = append(order, "start")
+		return nil
+	})
+	c.OnShutdown("database", func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("shutdown hook context has no deadline")
+		}
+		if !c.ShuttingDown() {
+			t.Error("ShuttingDown() = false during shutdown hooks")
+		}
+		order = append(order, "database")
+		return nil
+	})
+	c.OnShutdown("workers", func(ctx context.Context) error {
+		order = append(order, "workers")
+		return nil
+	})
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- c.ListenAndServe()
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	c.Stop()
+	c.Stop() // must be safe to call twice
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Errorf("ListenAndServe() error = %v, want nil", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("ListenAndServe() did not return after Stop()")
+	}
+
+	if want := []string{"start", "workers", "database"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("hook order = %v, want %v", order, want)
+	}
+}
+
+func TestCeleritas_ListenAndServe_StartHookFails(t *testing.T) {
+	c := &Celeritas{}
+	c.config.Port = "0"
+	c.config.ShutdownTimeout = time.Second
+	c.InfoLog = log.New(io.Discard, "", 0)
+	c.ErrorLog = log.New(io.Discard, "", 0)
+	c.Routes = chi.NewRouter()
+
+	var closed bool
+	c.OnShutdown("database", func(ctx context.Context) error {
+		closed = true
+		return nil
+	})
+	c.OnStart("migrate", func(ctx context.Context) error {
+		return errors.New("boom")
+	})
+
+	err := c.ListenAndServe()
+	if err == nil || !strings.Contains(err.Error(), `start hook "migrate"`) {
+		t.Errorf("ListenAndServe() error = %v, want the start hook's failure", err)
+	}
+	if !closed {
+		t.Error("ListenAndServe() did not run the shutdown hooks after a start hook failed")
+	}
+}
+
+func TestCeleritas_setup_failureRunsShutdownHooks(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "logs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.Log.File = "app.log"
+	cfg.Database.Type = "sqlite"
+	cfg.Database.Path = "app.db"
+	cfg.Database.AutoMigrate = AutoMigrateConfig{Enabled: true, LockTimeout: time.Second}
+	broken := fstest.MapFS{"1_broken.up.sql": {Data: []byte("this is not sql;")}}
+
+	// NewApp returns no application on failure, so the steps are run directly
+	c := &Celeritas{}
+	err := c.setup(options{rootPath: root, config: &cfg, migrations: broken})
+	if err == nil || !strings.Contains(err.Error(), "running migrations") {
+		t.Fatalf("setup() error = %v, want the failed migration", err)
+	}
+
+	if err := c.DB.Pool.Ping(); err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Errorf("database pool Ping() = %v, want the pool closed", err)
+	}
+	c.Logger.Info("after the failure")
+	logged, err := os.ReadFile(filepath.Join(root, "logs", "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(logged), "after the failure") {
+		t.Error("log file was still open after setup failed")
+	}
+}
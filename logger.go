@@ -0,0 +1,241 @@
+package celeritas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// LogConfig holds the settings for the application logger.
+type LogConfig struct {
+	Format     string `env:"LOG_FORMAT" default:"text"`  // text or json
+	Level      string `env:"LOG_LEVEL" default:"info"`   // debug, info, warn or error
+	File       string `env:"LOG_FILE"`                   // file name inside logs/; empty logs to stdout only
+	MaxSize    int    `env:"LOG_MAX_SIZE" default:"100"` // megabytes before the file is rotated
+	MaxBackups int    `env:"LOG_MAX_BACKUPS" default:"5"`
+}
+
+// validate checks the logger settings.
+func (l LogConfig) validate() []string {
+	var problems []string
+	if l.Format != "text" && l.Format != "json" {
+		problems = append(problems, fmt.Sprintf("LOG_FORMAT: must be one of text, json (got %q)", l.Format))
+	}
+	if _, err := parseLogLevel(l.Level); err != nil {
+		problems = append(problems, fmt.Sprintf("LOG_LEVEL: %v", err))
+	}
+	if l.File != "" && l.MaxSize <= 0 {
+		problems = append(problems, "LOG_MAX_SIZE: must be greater than zero when LOG_FILE is set")
+	}
+	return problems
+}
+
+// parseLogLevel converts a level name to a slog.Level.
+func parseLogLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return l, fmt.Errorf("must be one of debug, info, warn, error (got %q)", level)
+	}
+	return l, nil
+}
+
+// newLogger builds the application logger described by cfg. When cfg.File is
+// set, records are written to standard output and to a size-rotated file in
+// rootPath/logs, and the returned closer closes that file; otherwise the
+// closer is nil.
+func newLogger(cfg LogConfig, rootPath string) (*slog.Logger, io.Closer, error) {
+	level, err := parseLogLevel(cfg.Level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var out io.Writer = os.Stdout
+	var closer io.Closer
+	if cfg.File != "" {
+		file, err := newRotatingFile(
+			filepath.Join(rootPath, "logs", cfg.File),
+			int64(cfg.MaxSize)*1024*1024,
+			cfg.MaxBackups,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = io.MultiWriter(os.Stdout, file)
+		closer = file
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if cfg.Format == "json" {
+		handler = slog.NewJSONHandler(out, opts)
+	} else {
+		handler = slog.NewTextHandler(out, opts)
+	}
+
+	return slog.New(&contextHandler{Handler: handler}), closer, nil
+}
+
+// withRequestContext returns l with its handler wrapped in a contextHandler,
+// unless it is wrapped already, so a logger supplied with WithLogger records
+// request_id and user_id like the one built from the LOG_* settings.
+func withRequestContext(l *slog.Logger) *slog.Logger {
+	if _, ok := l.Handler().(*contextHandler); ok {
+		return l
+	}
+	return slog.New(&contextHandler{Handler: l.Handler()})
+}
+
+// logger returns the application logger. Applications assembled by hand
+// without New or NewApp may only have InfoLog set, in which case records are
+// written through it.
+func (c *Celeritas) logger() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	if c.InfoLog != nil {
+		return slog.New(slog.NewTextHandler(c.InfoLog.Writer(), nil))
+	}
+	return slog.Default()
+}
+
+type logContextKey struct{}
+
+// LogContext is middleware that records the logged-in user's ID in the
+// request context, so that records logged with the request's context carry
+// user_id alongside the request_id set by chi's RequestID middleware. It must
+// run after SessionLoad.
+func (c *Celeritas) LogContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.Session != nil {
+			if userID := c.Session.Get(r.Context(), "userID"); userID != nil {
+				r = r.WithContext(context.WithValue(r.Context(), logContextKey{}, userID))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// contextHandler adds request-scoped attributes found in the context to every
+// record, so handlers only need to log with r.Context().
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id := middleware.GetReqID(ctx); id != "" {
+			r.AddAttrs(slog.String("request_id", id))
+		}
+		if userID := ctx.Value(logContextKey{}); userID != nil {
+			r.AddAttrs(slog.Any("user_id", userID))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// rotatingFile is an io.WriteCloser that renames the file to name.1 once it
+// reaches maxSize bytes, shifting older backups up and keeping at most
+// maxBackups of them.
+type rotatingFile struct {
+	mu         sync.Mutex
+	name       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func newRotatingFile(name string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	f := &rotatingFile{name: name, maxSize: maxSize, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// a failed rotation leaves the current file open, so the record is
+	// still written and rotation is tried again on the next write
+	var rotateErr error
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		rotateErr = f.rotate()
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err == nil && rotateErr != nil {
+		err = fmt.Errorf("rotating %s: %w", f.name, rotateErr)
+	}
+	return n, err
+}
+
+// rotate closes the current file, shifts the backups and opens a fresh file.
+// If the current file cannot be moved aside it is reopened, so writes carry
+// on appending to it.
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	if err := f.shift(); err != nil {
+		return errors.Join(err, f.open())
+	}
+	return f.open()
+}
+
+// shift moves the current file to the first backup, and each backup to the
+// next, dropping the oldest. With no backups the current file is removed.
+func (f *rotatingFile) shift() error {
+	if f.maxBackups <= 0 {
+		if err := os.Remove(f.name); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	_ = os.Remove(fmt.Sprintf("%s.%d", f.name, f.maxBackups))
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", f.name, i), fmt.Sprintf("%s.%d", f.name, i+1))
+	}
+	return os.Rename(f.name, f.name+".1")
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
@@ -0,0 +1,203 @@
+package celeritas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     LogConfig
+		wantErr bool
+	}{
+		{name: "text to stdout", cfg: LogConfig{Format: "text", Level: "info"}},
+		{name: "json to file", cfg: LogConfig{Format: "json", Level: "debug", File: "app.log", MaxSize: 1}},
+		{name: "invalid level", cfg: LogConfig{Format: "json", Level: "loud"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			root := ts.TempDir()
+			if err := os.Mkdir(filepath.Join(root, "logs"), 0755); err != nil {
+				ts.Fatal(err)
+			}
+
+			logger, closer, err := newLogger(tt.cfg, root)
+			if (err != nil) != tt.wantErr {
+				ts.Fatalf("newLogger() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			logger.Debug("hello", "answer", 42)
+
+			if tt.cfg.File == "" {
+				if closer != nil {
+					ts.Error("newLogger() returned a closer without a log file")
+				}
+				return
+			}
+			if err := closer.Close(); err != nil {
+				ts.Fatal(err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(root, "logs", tt.cfg.File))
+			if err != nil {
+				ts.Fatal(err)
+			}
+			var record map[string]any
+			if err := json.Unmarshal(data, &record); err != nil {
+				ts.Fatalf("log file is not JSON: %v\n%s", err, data)
+			}
+			if record["msg"] != "hello" || record["answer"] != float64(42) || record["level"] != "DEBUG" {
+				ts.Errorf("log record = %v, want msg=hello answer=42 level=DEBUG", record)
+			}
+		})
+	}
+}
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(&contextHandler{Handler: slog.NewJSONHandler(&buf, nil)}).With("app", "test")
+
+	ctx := context.WithValue(context.Background(), middleware.RequestIDKey, "req-1")
+	ctx = context.WithValue(ctx, logContextKey{}, 7)
+	logger.InfoContext(ctx, "handled")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record["request_id"] != "req-1" || record["user_id"] != float64(7) || record["app"] != "test" {
+		t.Errorf("log record = %v, want request_id, user_id and app attributes", record)
+	}
+}
+
+func TestNewApp_WithLogger_requestContext(t *testing.T) {
+	var buf bytes.Buffer
+	c, err := NewApp(
+		WithRootPath(t.TempDir()),
+		WithConfig(DefaultConfig()),
+		WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))),
+	)
+	if err != nil {
+		t.Fatalf("NewApp() unexpected error: %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), middleware.RequestIDKey, "req-2")
+	ctx = context.WithValue(ctx, logContextKey{}, 9)
+	c.Logger.InfoContext(ctx, "handled")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("log output is not JSON: %v\n%s", err, buf.Bytes())
+	}
+	if record["request_id"] != "req-2" || record["user_id"] != float64(9) {
+		t.Errorf("log record = %v, want the injected logger to record request_id and user_id", record)
+	}
+}
+
+func TestCeleritas_LogContext(t *testing.T) {
+	session := scs.New()
+	session.Lifetime = time.Hour
+	c := &Celeritas{Session: session}
+
+	var got any
+	handler := session.LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session.Put(r.Context(), "userID", 12)
+		c.LogContext(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Context().Value(logContextKey{})
+		})).ServeHTTP(w, r)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got != 12 {
+		t.Errorf("LogContext() user id in context = %v, want 12", got)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.log")
+	f, err := newRotatingFile(name, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		name:        "fourth\n",
+		name + ".1": "third\n",
+		name + ".2": "second\n",
+	}
+	for file, contents := range want {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Errorf("reading %s: %v", file, err)
+			continue
+		}
+		if string(data) != contents {
+			t.Errorf("%s = %q, want %q", filepath.Base(file), data, contents)
+		}
+	}
+	if _, err := os.Stat(name + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected at most 2 backups, found %s.3", filepath.Base(name))
+	}
+	if matches, _ := filepath.Glob(name + "*"); len(matches) != 3 {
+		t.Errorf("found files %s, want 3", strings.Join(matches, ", "))
+	}
+}
+
+func TestRotatingFile_renameFails(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.log")
+	// a non-empty directory where the backup goes cannot be removed or
+	// renamed over, so every rotation fails
+	if err := os.MkdirAll(filepath.Join(name+".1", "keep"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := newRotatingFile(name, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for i, line := range []string{"first\n", "second\n", "third\n"} {
+		n, err := f.Write([]byte(line))
+		if i > 0 && err == nil {
+			t.Errorf("Write(%q) error = nil, want the rotation error", line)
+		}
+		if n != len(line) {
+			t.Errorf("Write(%q) = %d, want %d", line, n, len(line))
+		}
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "first\nsecond\nthird\n"; string(data) != want {
+		t.Errorf("%s = %q, want %q", filepath.Base(name), data, want)
+	}
+}
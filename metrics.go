@@ -0,0 +1,159 @@
+package celeritas
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/polyglotdev/celeritasproject/cache"
+	"github.com/polyglotdev/celeritasproject/metrics"
+)
+
+// MetricsConfig holds the settings for the Prometheus metrics endpoint.
+type MetricsConfig struct {
+	Enabled bool   `env:"METRICS_ENABLED"`
+	Path    string `env:"METRICS_PATH" default:"/metrics"`
+}
+
+// NewCounter registers a counter with the application's metrics registry.
+// It panics if the name is invalid or already registered.
+func (c *Celeritas) NewCounter(name, help string, labels ...string) *metrics.Counter {
+	return c.Metrics.NewCounter(name, help, labels...)
+}
+
+// NewGauge registers a gauge with the application's metrics registry. It
+// panics if the name is invalid or already registered.
+func (c *Celeritas) NewGauge(name, help string, labels ...string) *metrics.Gauge {
+	return c.Metrics.NewGauge(name, help, labels...)
+}
+
+// httpMetrics holds the request metrics recorded by the metrics middleware.
+type httpMetrics struct {
+	requests *metrics.Counter
+	duration *metrics.Histogram
+}
+
+// requestMetrics registers the request metrics the first time the metrics
+// middleware is set up, so the application's router and the built-in
+// endpoints record to the same series.
+func (c *Celeritas) requestMetrics() *httpMetrics {
+	if c.reqMetrics == nil {
+		c.reqMetrics = &httpMetrics{
+			requests: c.Metrics.NewCounter("http_requests_total",
+				"HTTP requests processed, by method, route and status.", "method", "route", "status"),
+			duration: c.Metrics.NewHistogram("http_request_duration_seconds",
+				"HTTP request latency, by method and route.", nil, "method", "route"),
+		}
+	}
+	return c.reqMetrics
+}
+
+// setupMetrics registers the built-in collectors for the services the
+// application is using. It runs once the database and cache are in place;
+// collectors read their values at scrape time.
+func (c *Celeritas) setupMetrics(redisCache *cache.RedisCache) {
+	if c.DB.Pool != nil {
+		pool := c.DB.Pool
+		c.Metrics.NewGaugeFunc("db_open_connections", "Established database connections, in use and idle.",
+			func() float64 { return float64(pool.Stats().OpenConnections) })
+		c.Metrics.NewGaugeFunc("db_in_use_connections", "Database connections currently in use.",
+			func() float64 { return float64(pool.Stats().InUse) })
+		c.Metrics.NewGaugeFunc("db_idle_connections", "Idle database connections.",
+			func() float64 { return float64(pool.Stats().Idle) })
+		c.Metrics.NewGaugeFunc("db_max_open_connections", "Maximum number of open database connections.",
+			func() float64 { return float64(pool.Stats().MaxOpenConnections) })
+		c.Metrics.NewCounterFunc("db_wait_count_total", "Connections waited for because the pool was exhausted.",
+			func() float64 { return float64(pool.Stats().WaitCount) })
+		c.Metrics.NewCounterFunc("db_wait_duration_seconds_total", "Time spent waiting for a database connection.",
+			func() float64 { return pool.Stats().WaitDuration.Seconds() })
+		c.Metrics.NewCounterFunc("db_max_idle_closed_total", "Connections closed because of the idle connection limit.",
+			func() float64 { return float64(pool.Stats().MaxIdleClosed) })
+		c.Metrics.NewCounterFunc("db_max_lifetime_closed_total", "Connections closed because they reached their maximum lifetime.",
+			func() float64 { return float64(pool.Stats().MaxLifetimeClosed) })
+	}
+
+	if redisCache != nil {
+		pool := redisCache.Conn
+		c.Metrics.NewGaugeFunc("redis_pool_active_connections", "Redis connections in the pool, in use and idle.",
+			func() float64 { return float64(pool.Stats().ActiveCount) })
+		c.Metrics.NewGaugeFunc("redis_pool_idle_connections", "Idle Redis connections in the pool.",
+			func() float64 { return float64(pool.Stats().IdleCount) })
+	}
+
+	if c.Cache != nil {
+		c.Cache = &instrumentedCache{
+			Cacher: c.Cache,
+			hits:   c.Metrics.NewCounter("cache_hits_total", "Cache lookups that found the key."),
+			misses: c.Metrics.NewCounter("cache_misses_total", "Cache lookups that did not find the key."),
+			errors: c.Metrics.NewCounter("cache_errors_total", "Cache lookups that failed."),
+		}
+	}
+}
+
+// MetricsMiddleware counts requests and records their latency, labelled by
+// method, chi route pattern and status. Labelling by pattern rather than path
+// keeps the number of series bounded; requests that match no route are
+// labelled "unmatched".
+func (c *Celeritas) MetricsMiddleware(next http.Handler) http.Handler {
+	m := c.requestMetrics()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		// chi fills in the pattern while routing, so it is only known once
+		// the request has been handled
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				route = pattern
+			}
+		}
+
+		m.requests.Inc(r.Method, route, strconv.Itoa(status))
+		m.duration.Observe(time.Since(start).Seconds(), r.Method, route)
+	})
+}
+
+// instrumentedCache counts hits and misses for the Cacher it wraps.
+type instrumentedCache struct {
+	cache.Cacher
+	hits, misses, errors *metrics.Counter
+}
+
+func (i *instrumentedCache) Has(key string) (bool, error) {
+	ok, err := i.Cacher.Has(key)
+	switch {
+	case err != nil:
+		i.errors.Inc()
+	case ok:
+		i.hits.Inc()
+	default:
+		i.misses.Inc()
+	}
+	return ok, err
+}
+
+func (i *instrumentedCache) Get(key string) (any, error) {
+	v, err := i.Cacher.Get(key)
+	switch {
+	case err == nil:
+		i.hits.Inc()
+	case errors.Is(err, redis.ErrNil):
+		i.misses.Inc()
+	default:
+		i.errors.Inc()
+	}
+	return v, err
+}
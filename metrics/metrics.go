@@ -0,0 +1,348 @@
+// Package metrics provides counters, gauges and histograms exposed in the
+// Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the histogram buckets, in seconds, used when none are
+// given. They suit typical HTTP request latencies.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// collector is implemented by every metric type held by a Registry.
+type collector interface {
+	describe() (name, help, kind string)
+	write(w *bufio.Writer)
+}
+
+// Registry holds a set of metrics and renders them for scraping. The zero
+// value is not usable; use NewRegistry.
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]collector
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+// register adds c to the registry. Metric names and labels are fixed at
+// compile time in practice, so an invalid or duplicate name is a programming
+// error and panics, as with prometheus.MustRegister.
+func (r *Registry) register(c collector, labels []string) {
+	name, _, _ := c.describe()
+	if !namePattern.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	for _, l := range labels {
+		if !namePattern.MatchString(l) || strings.HasPrefix(l, "__") || l == "le" {
+			panic(fmt.Sprintf("metrics: invalid label name %q for %s", l, name))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.collectors[name]; exists {
+		panic(fmt.Sprintf("metrics: duplicate metric %q", name))
+	}
+	r.collectors[name] = c
+}
+
+// WritePrometheus writes every metric in the Prometheus text format, sorted
+// by name.
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	collectors := make([]collector, len(names))
+	for i, name := range names {
+		collectors[i] = r.collectors[name]
+	}
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		name, help, kind := c.describe()
+		fmt.Fprintf(bw, "# HELP %s %s\n", name, escapeHelp(help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", name, kind)
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler returns an http.Handler that serves the registry for scraping.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WritePrometheus(w)
+	})
+}
+
+// vec stores one value per combination of label values.
+type vec[T any] struct {
+	mu     sync.Mutex
+	labels []string
+	series map[string]*series[T]
+}
+
+type series[T any] struct {
+	values []string
+	value  T
+}
+
+func newVec[T any](labels []string) vec[T] {
+	return vec[T]{labels: labels, series: make(map[string]*series[T])}
+}
+
+// with returns the series for the given label values, creating it with init
+// on first use. The caller must hold v.mu.
+func (v *vec[T]) with(values []string, init func() T) *series[T] {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: got %d label values, want %d (%s)",
+			len(values), len(v.labels), strings.Join(v.labels, ", ")))
+	}
+	key := strings.Join(values, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = &series[T]{values: slices.Clone(values), value: init()}
+		v.series[key] = s
+	}
+	return s
+}
+
+// sorted returns the series ordered by label values so output is stable.
+// The caller must hold v.mu.
+func (v *vec[T]) sorted() []*series[T] {
+	out := make([]*series[T], 0, len(v.series))
+	for _, s := range v.series {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return slices.Compare(out[i].values, out[j].values) < 0
+	})
+	return out
+}
+
+// Counter is a value that only goes up, optionally partitioned by labels.
+type Counter struct {
+	name, help string
+	vec        vec[float64]
+}
+
+// NewCounter registers and returns a counter with the given label names.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{name: name, help: help, vec: newVec[float64](labels)}
+	r.register(c, labels)
+	return c
+}
+
+// Inc adds one to the series identified by labelValues.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta, which must not be negative, to the series identified by
+// labelValues.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot decrease", c.name))
+	}
+	c.vec.mu.Lock()
+	defer c.vec.mu.Unlock()
+	c.vec.with(labelValues, func() float64 { return 0 }).value += delta
+}
+
+func (c *Counter) describe() (string, string, string) { return c.name, c.help, "counter" }
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.vec.mu.Lock()
+	defer c.vec.mu.Unlock()
+	for _, s := range c.vec.sorted() {
+		writeSample(w, c.name, c.vec.labels, s.values, s.value)
+	}
+}
+
+// Gauge is a value that can go up and down, optionally partitioned by labels.
+type Gauge struct {
+	name, help string
+	vec        vec[float64]
+}
+
+// NewGauge registers and returns a gauge with the given label names.
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{name: name, help: help, vec: newVec[float64](labels)}
+	r.register(g, labels)
+	return g
+}
+
+// Set sets the series identified by labelValues to v.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.vec.mu.Lock()
+	defer g.vec.mu.Unlock()
+	g.vec.with(labelValues, func() float64 { return 0 }).value = v
+}
+
+// Add adds delta, which may be negative, to the series identified by
+// labelValues.
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.vec.mu.Lock()
+	defer g.vec.mu.Unlock()
+	g.vec.with(labelValues, func() float64 { return 0 }).value += delta
+}
+
+// Inc adds one to the series identified by labelValues.
+func (g *Gauge) Inc(labelValues ...string) { g.Add(1, labelValues...) }
+
+// Dec subtracts one from the series identified by labelValues.
+func (g *Gauge) Dec(labelValues ...string) { g.Add(-1, labelValues...) }
+
+func (g *Gauge) describe() (string, string, string) { return g.name, g.help, "gauge" }
+
+func (g *Gauge) write(w *bufio.Writer) {
+	g.vec.mu.Lock()
+	defer g.vec.mu.Unlock()
+	for _, s := range g.vec.sorted() {
+		writeSample(w, g.name, g.vec.labels, s.values, s.value)
+	}
+}
+
+// funcMetric reports a value computed at scrape time.
+type funcMetric struct {
+	name, help, kind string
+	fn               func() float64
+}
+
+// NewGaugeFunc registers a gauge whose value is computed by fn each time the
+// registry is scraped. It suits values owned by something else, such as
+// connection pool statistics.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{name: name, help: help, kind: "gauge", fn: fn}, nil)
+}
+
+// NewCounterFunc registers a counter whose value is computed by fn each time
+// the registry is scraped. fn must never return a smaller value than before.
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{name: name, help: help, kind: "counter", fn: fn}, nil)
+}
+
+func (f *funcMetric) describe() (string, string, string) { return f.name, f.help, f.kind }
+
+func (f *funcMetric) write(w *bufio.Writer) {
+	writeSample(w, f.name, nil, nil, f.fn())
+}
+
+// Histogram counts observations in configurable buckets, optionally
+// partitioned by labels.
+type Histogram struct {
+	name, help string
+	buckets    []float64
+	vec        vec[*histogramValue]
+}
+
+type histogramValue struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram registers and returns a histogram. Buckets are upper bounds
+// in increasing order; nil selects DefaultBuckets.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	if !slices.IsSorted(buckets) {
+		panic(fmt.Sprintf("metrics: buckets for %s must be in increasing order", name))
+	}
+	h := &Histogram{name: name, help: help, buckets: slices.Clone(buckets), vec: newVec[*histogramValue](labels)}
+	r.register(h, labels)
+	return h
+}
+
+// Observe records v in the series identified by labelValues.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.vec.mu.Lock()
+	defer h.vec.mu.Unlock()
+	s := h.vec.with(labelValues, func() *histogramValue {
+		return &histogramValue{counts: make([]uint64, len(h.buckets))}
+	})
+	if i, _ := slices.BinarySearch(h.buckets, v); i < len(h.buckets) {
+		s.value.counts[i]++
+	}
+	s.value.count++
+	s.value.sum += v
+}
+
+func (h *Histogram) describe() (string, string, string) { return h.name, h.help, "histogram" }
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.vec.mu.Lock()
+	defer h.vec.mu.Unlock()
+
+	labels := append(slices.Clone(h.vec.labels), "le")
+	for _, s := range h.vec.sorted() {
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += s.value.counts[i]
+			writeSample(w, h.name+"_bucket", labels, append(slices.Clone(s.values), formatFloat(upper)), float64(cumulative))
+		}
+		writeSample(w, h.name+"_bucket", labels, append(slices.Clone(s.values), "+Inf"), float64(s.value.count))
+		writeSample(w, h.name+"_sum", h.vec.labels, s.values, s.value.sum)
+		writeSample(w, h.name+"_count", h.vec.labels, s.values, float64(s.value.count))
+	}
+}
+
+// writeSample writes one sample line.
+func writeSample(w *bufio.Writer, name string, labels, values []string, v float64) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", l, escapeLabel(values[i]))
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
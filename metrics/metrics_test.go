@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WritePrometheus(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(r *Registry)
+		want  string
+	}{
+		{
+			name: "counter with labels",
+			setup: func(r *Registry) {
+				c := r.NewCounter("jobs_total", "Jobs run.", "queue")
+				c.Inc("mail")
+				c.Add(2, "default")
+				c.Inc("mail")
+			},
+			want: `# HELP jobs_total Jobs run.
+# TYPE jobs_total counter
+jobs_total{queue="default"} 2
+jobs_total{queue="mail"} 2
+`,
+		},
+		{
+			name: "gauge without labels",
+			setup: func(r *Registry) {
+				g := r.NewGauge("workers", "Running workers.")
+				g.Set(3)
+				g.Dec()
+			},
+			want: `# HELP workers Running workers.
+# TYPE workers gauge
+workers 2
+`,
+		},
+		{
+			name: "gauge func",
+			setup: func(r *Registry) {
+				r.NewGaugeFunc("answer", "The answer.", func() float64 { return 42 })
+			},
+			want: `# HELP answer The answer.
+# TYPE answer gauge
+answer 42
+`,
+		},
+		{
+			name: "histogram",
+			setup: func(r *Registry) {
+				h := r.NewHistogram("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+				h.Observe(0.05, "/")
+				h.Observe(0.5, "/")
+				h.Observe(2, "/")
+			},
+			want: `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/",le="0.1"} 1
+latency_seconds_bucket{route="/",le="1"} 2
+latency_seconds_bucket{route="/",le="+Inf"} 3
+latency_seconds_sum{route="/"} 2.55
+latency_seconds_count{route="/"} 3
+`,
+		},
+		{
+			name: "escaping",
+			setup: func(r *Registry) {
+				r.NewCounter("escaped_total", "Line one\nline two.", "v").Inc(`a "b" \c`)
+			},
+			want: `# HELP escaped_total Line one\nline two.
+# TYPE escaped_total counter
+escaped_total{v="a \"b\" \\c"} 1
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			r := NewRegistry()
+			tt.setup(r)
+
+			var buf bytes.Buffer
+			if err := r.WritePrometheus(&buf); err != nil {
+				ts.Fatalf("WritePrometheus() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				ts.Errorf("WritePrometheus() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_RegisterPanics(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(r *Registry)
+	}{
+		{"invalid name", func(r *Registry) { r.NewCounter("bad-name", "") }},
+		{"reserved label", func(r *Registry) { r.NewHistogram("h", "", nil, "le") }},
+		{"duplicate", func(r *Registry) {
+			r.NewCounter("dup", "")
+			r.NewGauge("dup", "")
+		}},
+		{"wrong label count", func(r *Registry) { r.NewCounter("c", "", "a").Inc() }},
+		{"negative counter", func(r *Registry) { r.NewCounter("n", "").Add(-1) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			defer func() {
+				if recover() == nil {
+					ts.Error("expected panic")
+				}
+			}()
+			tt.setup(NewRegistry())
+		})
+	}
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("hits_total", "Hits.").Inc()
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Handler() Content-Type = %q, want Prometheus text format", ct)
+	}
+	if !strings.Contains(w.Body.String(), "hits_total 1\n") {
+		t.Errorf("Handler() body = %q, want hits_total sample", w.Body.String())
+	}
+}
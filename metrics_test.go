@@ -0,0 +1,110 @@
+package celeritas
+
+import (
+	"bytes"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alexedwards/scs/v2/memstore"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/gomodule/redigo/redis"
+)
+
+// mapCache is a minimal in-memory Cacher for tests.
+type mapCache map[string]any
+
+func (m mapCache) Has(key string) (bool, error) { _, ok := m[key]; return ok, nil }
+func (m mapCache) Get(key string) (any, error) {
+	v, ok := m[key]
+	if !ok {
+		return nil, redis.ErrNil
+	}
+	return v, nil
+}
+func (m mapCache) Set(key string, v any, _ ...int) error { m[key] = v; return nil }
+func (m mapCache) Forget(key string) error               { delete(m, key); return nil }
+func (m mapCache) EmptyByMatch(string) error             { return nil }
+func (m mapCache) Empty() error                          { clear(m); return nil }
+
+func TestCeleritas_Metrics(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Metrics.Enabled = true
+	cfg.AccessLog.Format = "off"
+	cfg.Database.Type = "postgres"
+	cfg.Database.User = "app"
+	cfg.Database.Name = "app"
+
+	db, err := sql.Open("pgx", "host=localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	c, err := NewApp(
+		WithRootPath(t.TempDir()),
+		WithConfig(cfg),
+		WithDB(db),
+		WithCache(mapCache{"hit": 1}),
+		WithSessionStore(memstore.New()),
+		WithLogger(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))),
+	)
+	if err != nil {
+		t.Fatalf("NewApp() unexpected error: %v", err)
+	}
+
+	orders := c.NewCounter("orders_total", "Orders placed.")
+	orders.Inc()
+
+	// the metrics endpoint is not on Routes, so middleware can still be added
+	c.Routes.Use(middleware.NoCache)
+	c.Routes.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+	for _, path := range []string{"/users/1", "/users/2", "/missing", "/healthz"} {
+		c.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	_, _ = c.Cache.Get("hit")
+	_, _ = c.Cache.Get("miss")
+	_, _ = c.Cache.Has("miss")
+
+	w := httptest.NewRecorder()
+	c.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /metrics status = %d, want 200", w.Code)
+	}
+	body := w.Body.String()
+
+	for _, want := range []string{
+		`http_requests_total{method="GET",route="/users/{id}",status="202"} 2`,
+		`http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`http_requests_total{method="GET",route="/healthz",status="200"} 1`,
+		`http_request_duration_seconds_count{method="GET",route="/users/{id}"} 2`,
+		"# TYPE db_open_connections gauge",
+		"db_max_open_connections 0",
+		"cache_hits_total 1",
+		"cache_misses_total 2",
+		"orders_total 1",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("GET /metrics missing %q in:\n%s", want, body)
+		}
+	}
+}
+
+func TestCeleritas_MetricsDisabled(t *testing.T) {
+	c, err := NewApp(WithRootPath(t.TempDir()), WithConfig(DefaultConfig()), WithSessionStore(memstore.New()))
+	if err != nil {
+		t.Fatalf("NewApp() unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	c.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET /metrics status = %d, want 404 when metrics are disabled", w.Code)
+	}
+}
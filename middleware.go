@@ -2,7 +2,6 @@ package celeritas
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/justinas/nosurf"
 )
@@ -13,17 +12,15 @@ func (c *Celeritas) SessionLoad(next http.Handler) http.Handler {
 
 func (c *Celeritas) NoSurf(next http.Handler) http.Handler {
 	csrfHandler := nosurf.New(next)
-	secure, _ := strconv.ParseBool(c.config.cookie.secure)
-
 	csrfHandler.ExemptGlob("/api/*")
 
-	if !c.Debug {
+	if !c.devMode() {
 		csrfHandler.SetBaseCookie(http.Cookie{
 			HttpOnly: true,
 			Path:     "/",
-			Secure:   secure,
+			Secure:   c.config.Cookie.Secure,
 			SameSite: http.SameSiteStrictMode,
-			Domain:   c.config.cookie.domain,
+			Domain:   c.config.Cookie.Domain,
 		})
 	} else {
 		csrfHandler.SetBaseCookie(http.Cookie{
@@ -1,17 +1,72 @@
 package celeritas
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	_ "github.com/golang-migrate/migrate/v4/database/mysql"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
 )
 
+// migrationsDir returns the folder the migration files are read from when
+// Migrations is not set.
+func (c *Celeritas) migrationsDir() string {
+	return filepath.Join(c.RootPath, "migrations")
+}
+
+// migrationFiles returns the migration files: Migrations, when set, or the
+// migrations folder under the root path.
+func (c *Celeritas) migrationFiles() fs.FS {
+	if c.Migrations != nil {
+		return c.Migrations
+	}
+	return os.DirFS(c.migrationsDir())
+}
+
+// newMigrate returns a migrate instance for dsn that runs the migration files
+// and the registered Go migrations, in version order. The files are read
+// through fs.FS, so an embedded copy works the same as the folder on disk.
+// golang-migrate's iofs source reads an fs.FS too, but it has no way to add
+// the Go migrations, so migrationSource takes its place. Go migrations run on
+// the application's connection pool, c.DB.
+func (c *Celeritas) newMigrate(dsn string) (*migrate.Migrate, error) {
+	src, err := newMigrationSource(c.migrationFiles())
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := database.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	scheme, _, _ := strings.Cut(dsn, "://")
+	driver := &migrationDriver{Driver: db, db: c.DB, goFuncs: src.goFuncs}
+
+	m, err := migrate.NewWithInstance("celeritas", src, scheme, driver)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *Celeritas) MigrateUp(dsn string) error {
-	m, err := migrate.New("file://"+c.RootPath+"/migrations", dsn)
+	m, err := c.newMigrate(dsn)
 	if err != nil {
 		return err
 	}
@@ -24,7 +79,7 @@ func (c *Celeritas) MigrateUp(dsn string) error {
 }
 
 func (c *Celeritas) MigrateDownAll(dsn string) error {
-	m, err := migrate.New("file://"+c.RootPath+"/migrations", dsn)
+	m, err := c.newMigrate(dsn)
 	if err != nil {
 		return err
 	}
@@ -38,7 +93,7 @@ func (c *Celeritas) MigrateDownAll(dsn string) error {
 }
 
 func (c *Celeritas) Steps(n int, dsn string) error {
-	m, err := migrate.New("file://"+c.RootPath+"/migrations", dsn)
+	m, err := c.newMigrate(dsn)
 	if err != nil {
 		return err
 	}
@@ -51,16 +106,380 @@ func (c *Celeritas) Steps(n int, dsn string) error {
 	return nil
 }
 
-func (c *Celeritas) MigrateForce(dsn string) error {
-	m, err := migrate.New("file://"+c.RootPath+"/migrations", dsn)
+// MigrateForce sets the recorded schema version to version and clears the
+// dirty flag, without running any migration. Use it to recover after a
+// migration failed part way through and the schema has been fixed by hand.
+// A version of -1 records that no migration has run.
+func (c *Celeritas) MigrateForce(version int, dsn string) error {
+	m, err := c.newMigrate(dsn)
 	if err != nil {
 		return err
 	}
 	defer m.Close()
 
-	if err := m.Force(-1); err != nil {
+	if err := m.Force(version); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// MigrateGoto migrates up or down until the schema is at version.
+func (c *Celeritas) MigrateGoto(version uint, dsn string) error {
+	m, err := c.newMigrate(dsn)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	return m.Migrate(version)
+}
+
+// MigrateVersion returns the schema's current version and whether the last
+// migration failed part way, leaving it dirty. The version is 0 when no
+// migration has run.
+func (c *Celeritas) MigrateVersion(dsn string) (version uint, dirty bool, err error) {
+	m, err := c.newMigrate(dsn)
+	if err != nil {
+		return 0, false, err
+	}
+	defer m.Close()
+
+	version, dirty, err = m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// MigrationStatus describes one migration and whether it has run.
+type MigrationStatus struct {
+	Version uint
+	Name    string
+	Applied bool
+	Dirty   bool // the migration failed part way and needs fixing
+}
+
+// MigrateStatus lists the up migrations, from the migration files and the
+// registered Go migrations, in version order. Migrations run in order, so
+// every migration up to the schema's current version is applied and the rest
+// are pending.
+func (c *Celeritas) MigrateStatus(dsn string) ([]MigrationStatus, error) {
+	current, dirty, err := c.MigrateVersion(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := newMigrationSource(c.migrationFiles())
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, m := range src.upMigrations() {
+		statuses = append(statuses, MigrationStatus{
+			Version: m.Version,
+			Name:    m.Identifier,
+			Applied: m.Version <= current,
+			Dirty:   dirty && m.Version == current,
+		})
+	}
+	return statuses, nil
+}
+
+// RunMigrations loads the configuration in rootPath and runs a migrate
+// command against the configured database. It is what `celeritas migrate`
+// runs. The commands are up, down (one migration, or every migration when arg
+// is "all"), reset, status, version, goto <version>, steps <n> and
+// force <version>; status and version write their report to w.
+//
+// With dryRun, up, down and steps write the migrations they would run to w
+// instead, and return ErrPendingMigrations if there are any. A dry run only
+// reads the database, and does not create a SQLite database that does not
+// exist yet.
+//
+// The migration files are read from the migrations folder under rootPath,
+// unless opts include WithMigrations. Other options are ignored. When Go
+// migrations are registered it also connects to the database, so that they
+// have a connection pool to run on.
+func RunMigrations(rootPath string, w io.Writer, command, arg string, dryRun bool, opts ...Option) error {
+	cfg, err := LoadConfig(rootPath)
+	if err != nil {
+		return err
+	}
+	dsn := cfg.Database.withRoot(rootPath).MigrateURL()
+	if dsn == "" {
+		return fmt.Errorf("no migration support for DATABASE_TYPE %q", cfg.Database.Type)
+	}
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	c := &Celeritas{RootPath: rootPath, Migrations: o.migrations}
+	c.DB.DataType = cfg.Database.Type
+	if dryRun {
+		db := cfg.Database.withRoot(rootPath)
+		// nothing has been applied to a SQLite database that does not exist
+		// yet, and connecting to it would create it
+		if db.Type == "sqlite" {
+			if _, err := os.Stat(db.Path); errors.Is(err, fs.ErrNotExist) {
+				db.Path = ":memory:"
+			}
+		}
+		pool, err := c.OpenDB(db.Type, db.DSN())
+		if err != nil {
+			return fmt.Errorf("connecting to %s database: %w", db.Type, err)
+		}
+		defer pool.Close()
+		c.DB.Pool = pool
+		return c.dryRun(w, command, arg)
+	}
+	if hasGoMigrations() {
+		pool, err := c.OpenDB(cfg.Database.Type, cfg.Database.withRoot(rootPath).DSN())
+		if err != nil {
+			return fmt.Errorf("connecting to %s database: %w", cfg.Database.Type, err)
+		}
+		defer pool.Close()
+		c.DB.Pool = pool
+	}
+
+	switch command {
+	case "up":
+		return c.MigrateUp(dsn)
+
+	case "down":
+		if arg == "all" {
+			return c.MigrateDownAll(dsn)
+		}
+		return c.Steps(-1, dsn)
+
+	case "reset":
+		if err := c.MigrateDownAll(dsn); err != nil {
+			return err
+		}
+		return c.MigrateUp(dsn)
+
+	case "status":
+		statuses, err := c.MigrateStatus(dsn)
+		if err != nil {
+			return err
+		}
+		if len(statuses) == 0 {
+			fmt.Fprintf(w, "No migrations found in %s\n", c.migrationsDir())
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Dirty {
+				state = "dirty"
+			} else if s.Applied {
+				state = "applied"
+			}
+			fmt.Fprintf(w, "%-8s %-20d %s\n", state, s.Version, s.Name)
+		}
+		return nil
+
+	case "version":
+		version, dirty, err := c.MigrateVersion(dsn)
+		if err != nil {
+			return err
+		}
+		switch {
+		case version == 0:
+			fmt.Fprintln(w, "No migrations have been run")
+		case dirty:
+			fmt.Fprintf(w, "Version %d (dirty: fix the schema, then run migrate force %d)\n", version, version)
+		default:
+			fmt.Fprintf(w, "Version %d\n", version)
+		}
+		return nil
+
+	case "goto":
+		version, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("migrate goto requires a version number (got %q)", arg)
+		}
+		return c.MigrateGoto(uint(version), dsn)
+
+	case "steps":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n == 0 {
+			return fmt.Errorf("migrate steps requires a non-zero number, negative to roll back (got %q)", arg)
+		}
+		return c.Steps(n, dsn)
+
+	case "force":
+		version, err := strconv.Atoi(arg)
+		if err != nil || version < -1 {
+			return fmt.Errorf("migrate force requires a version number, or -1 for none (got %q)", arg)
+		}
+		return c.MigrateForce(version, dsn)
+	}
+	return fmt.Errorf("unknown migrate command %q", command)
+}
+
+// ErrPendingMigrations is returned by a dry run that found migrations to run,
+// so deploy checks can fail until the database has been migrated.
+var ErrPendingMigrations = errors.New("pending migrations")
+
+// PlannedMigration is a migration that a migrate command would run.
+type PlannedMigration struct {
+	Version   uint
+	Name      string
+	Direction string // up or down
+	File      string // the file in the migrations folder, or "" for a Go migration
+	SQL       string // the file's contents, or a comment for a Go migration
+}
+
+// migrationsTable is the table golang-migrate records the schema version in.
+const migrationsTable = "schema_migrations"
+
+// schemaVersion reads the schema version from the version table on the
+// primary pool. Unlike MigrateVersion it does not go through golang-migrate,
+// which creates the version table when it connects, so it changes nothing. A
+// database without the version table has never been migrated and is at
+// version 0.
+func (c *Celeritas) schemaVersion(ctx context.Context) (version uint, dirty bool, err error) {
+	pool := c.DB.Writer()
+	if pool == nil {
+		return 0, false, errors.New("reading the schema version needs a database connection")
+	}
+
+	var exists string
+	switch driverName(c.DB.DataType) {
+	case "pgx":
+		exists = "select count(*) from pg_tables where schemaname = current_schema() and tablename = $1"
+	case "mysql":
+		exists = "select count(*) from information_schema.tables where table_schema = database() and table_name = ?"
+	case "sqlite":
+		exists = "select count(*) from sqlite_master where type = 'table' and name = ?"
+	default:
+		return 0, false, fmt.Errorf("no migration support for DATABASE_TYPE %q", c.DB.DataType)
+	}
+	var tables int
+	if err := pool.QueryRowContext(ctx, exists, migrationsTable).Scan(&tables); err != nil {
+		return 0, false, err
+	}
+	if tables == 0 {
+		return 0, false, nil
+	}
+
+	var v int64
+	err = pool.QueryRowContext(ctx, "select version, dirty from "+migrationsTable+" limit 1").Scan(&v, &dirty)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, false, nil // every migration was rolled back
+	case err != nil:
+		return 0, false, err
+	case v < 0:
+		return 0, dirty, nil
+	}
+	return uint(v), dirty, nil
+}
+
+// MigratePlan returns the migrations that Steps(steps, dsn) would run, in the
+// order it would run them. A steps of 0 plans every pending up migration,
+// like MigrateUp, and a negative steps larger than the number applied plans
+// rolling everything back, like MigrateDownAll. It errors if the schema is
+// dirty, as migrating would.
+//
+// It only reads the version table, through the application's connection
+// pool, so it changes nothing in the database, not even creating the version
+// table on a database that has never been migrated.
+func (c *Celeritas) MigratePlan(steps int) ([]PlannedMigration, error) {
+	current, dirty, err := c.schemaVersion(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, fmt.Errorf("the schema is dirty at version %d; fix it, then run migrate force %d", current, current)
+	}
+
+	src, err := newMigrationSource(c.migrationFiles())
+	if err != nil {
+		return nil, err
+	}
+
+	var plan []PlannedMigration
+	add := func(m *source.Migration) error {
+		p := PlannedMigration{Version: m.Version, Name: m.Identifier, Direction: string(m.Direction), File: m.Raw}
+		if _, ok := src.goFuncs[m.Version]; ok {
+			p.SQL = fmt.Sprintf("-- runs the Go %s function registered for version %d", m.Direction, m.Version)
+		} else {
+			body, err := fs.ReadFile(src.fsys, m.Raw)
+			if err != nil {
+				return err
+			}
+			p.SQL = string(body)
+		}
+		plan = append(plan, p)
+		return nil
+	}
+
+	if steps >= 0 {
+		version, ok := src.migrations.First()
+		if current != 0 {
+			version, ok = src.migrations.Next(current)
+		}
+		for n := 0; ok && (steps == 0 || n < steps); n++ {
+			if m, found := src.migrations.Up(version); found {
+				if err := add(m); err != nil {
+					return nil, err
+				}
+			}
+			version, ok = src.migrations.Next(version)
+		}
+		return plan, nil
+	}
+
+	version, ok := current, current != 0
+	for n := 0; ok && steps < -n; n++ {
+		if m, found := src.migrations.Down(version); found {
+			if err := add(m); err != nil {
+				return nil, err
+			}
+		}
+		version, ok = src.migrations.Prev(version)
+	}
+	return plan, nil
+}
+
+// dryRun writes the plan for a migrate up, down or steps command to w.
+func (c *Celeritas) dryRun(w io.Writer, command, arg string) error {
+	var steps int
+	switch command {
+	case "up":
+	case "down":
+		steps = -1
+		if arg == "all" {
+			steps = math.MinInt
+		}
+	case "steps":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n == 0 {
+			return fmt.Errorf("migrate steps requires a non-zero number, negative to roll back (got %q)", arg)
+		}
+		steps = n
+	default:
+		return fmt.Errorf("migrate %s does not support --dry-run; use it with up, down or steps", command)
+	}
+
+	plan, err := c.MigratePlan(steps)
+	if err != nil {
+		return err
+	}
+	if len(plan) == 0 {
+		fmt.Fprintln(w, "No migrations to run")
+		return nil
+	}
+	for _, p := range plan {
+		file := p.File
+		if file == "" {
+			file = "Go migration"
+		}
+		fmt.Fprintf(w, "-- migration %d %s %s: %s\n", p.Version, p.Name, p.Direction, file)
+		fmt.Fprintln(w, strings.TrimRight(p.SQL, "\n"))
+		fmt.Fprintln(w)
+	}
+	return fmt.Errorf("%w: %d to run", ErrPendingMigrations, len(plan))
+}
@@ -0,0 +1,276 @@
+package celeritas
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// newMigrationApp returns an application on a SQLite database with three
+// migrations, and the URL to migrate its database with.
+func newMigrationApp(t *testing.T) (*Celeritas, string) {
+	t.Helper()
+	c, _ := newSQLiteApp(t, nil)
+	writeMigrations(t, c.RootPath, map[string]string{
+		"1_create_a.up.sql":          "create table a (id integer);",
+		"1_create_a.down.sql":        "drop table a;",
+		"2_create_b.sqlite.up.sql":   "create table b (id integer);",
+		"2_create_b.sqlite.down.sql": "drop table b;",
+		"3_broken.up.sql":            "create table c (id integer); this is not sql;",
+		"3_broken.down.sql":          "drop table if exists c;",
+		"README.md":                  "not a migration",
+	})
+	return c, c.MigrateURL()
+}
+
+func TestCeleritas_MigrateStatus(t *testing.T) {
+	c, dsn := newMigrationApp(t)
+
+	version, dirty, err := c.MigrateVersion(dsn)
+	if err != nil || version != 0 || dirty {
+		t.Fatalf("MigrateVersion() = %d, %v, %v, want 0, false, nil", version, dirty, err)
+	}
+
+	if err := c.Steps(2, dsn); err != nil {
+		t.Fatalf("Steps(2) unexpected error: %v", err)
+	}
+	statuses, err := c.MigrateStatus(dsn)
+	if err != nil {
+		t.Fatalf("MigrateStatus() unexpected error: %v", err)
+	}
+	want := []MigrationStatus{
+		{Version: 1, Name: "create_a", Applied: true},
+		{Version: 2, Name: "create_b.sqlite", Applied: true},
+		{Version: 3, Name: "broken"},
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("MigrateStatus() = %+v, want %+v", statuses, want)
+	}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Errorf("MigrateStatus()[%d] = %+v, want %+v", i, statuses[i], want[i])
+		}
+	}
+
+	// the broken migration leaves the schema dirty at version 3
+	if err := c.MigrateUp(dsn); err == nil {
+		t.Fatal("MigrateUp() unexpected success with a broken migration")
+	}
+	statuses, _ = c.MigrateStatus(dsn)
+	if last := statuses[2]; !last.Applied || !last.Dirty {
+		t.Errorf("MigrateStatus() broken migration = %+v, want applied and dirty", last)
+	}
+
+	if err := c.MigrateForce(2, dsn); err != nil {
+		t.Fatalf("MigrateForce(2) unexpected error: %v", err)
+	}
+	version, dirty, err = c.MigrateVersion(dsn)
+	if err != nil || version != 2 || dirty {
+		t.Errorf("MigrateVersion() after force = %d, %v, %v, want 2, false, nil", version, dirty, err)
+	}
+
+	if err := c.MigrateGoto(1, dsn); err != nil {
+		t.Fatalf("MigrateGoto(1) unexpected error: %v", err)
+	}
+	if version, _, _ := c.MigrateVersion(dsn); version != 1 {
+		t.Errorf("MigrateVersion() after goto = %d, want 1", version)
+	}
+
+	if err := c.MigrateForce(-1, dsn); err != nil {
+		t.Fatalf("MigrateForce(-1) unexpected error: %v", err)
+	}
+	if version, _, _ := c.MigrateVersion(dsn); version != 0 {
+		t.Errorf("MigrateVersion() after force -1 = %d, want 0", version)
+	}
+}
+
+func TestNewApp_WithMigrations(t *testing.T) {
+	migrations := fstest.MapFS{
+		"1_create_a.up.sql":   {Data: []byte("create table a (id integer);")},
+		"1_create_a.down.sql": {Data: []byte("drop table a;")},
+		"2_create_b.up.sql":   {Data: []byte("create table b (id integer);")},
+		"2_create_b.down.sql": {Data: []byte("drop table b;")},
+	}
+
+	// the root path has no migrations folder, so the files can only come
+	// from the embedded copy
+	c, _ := newSQLiteApp(t, nil, WithMigrations(migrations))
+
+	if err := c.MigrateUp(c.MigrateURL()); err != nil {
+		t.Fatalf("MigrateUp() unexpected error: %v", err)
+	}
+	if version, _, _ := c.MigrateVersion(c.MigrateURL()); version != 2 {
+		t.Errorf("MigrateVersion() = %d, want 2", version)
+	}
+	if _, err := c.DB.Pool.Exec("insert into b (id) values (1)"); err != nil {
+		t.Errorf("table from the embedded migration is missing: %v", err)
+	}
+
+	if err := c.Steps(-1, c.MigrateURL()); err != nil {
+		t.Fatalf("Steps(-1) unexpected error: %v", err)
+	}
+	statuses, err := c.MigrateStatus(c.MigrateURL())
+	if err != nil || len(statuses) != 2 || !statuses[0].Applied || statuses[1].Applied {
+		t.Errorf("MigrateStatus() = %+v, %v, want 1 applied and 2 pending", statuses, err)
+	}
+}
+
+func TestCeleritas_Migrations(t *testing.T) {
+	migrations := fstest.MapFS{
+		"1_create_a.up.sql": {Data: []byte("create table a (id integer);")},
+	}
+
+	t.Run("set after New", func(ts *testing.T) {
+		c, _ := newSQLiteApp(ts, nil)
+		c.Migrations = migrations
+		if err := c.MigrateUp(c.MigrateURL()); err != nil {
+			ts.Fatalf("MigrateUp() unexpected error: %v", err)
+		}
+		if _, err := c.DB.Pool.Exec("insert into a (id) values (1)"); err != nil {
+			ts.Errorf("table from the embedded migration is missing: %v", err)
+		}
+	})
+
+	t.Run("RunMigrations", func(ts *testing.T) {
+		root := ts.TempDir()
+		env := "DATABASE_TYPE=sqlite\nDATABASE_PATH=app.db\n"
+		if err := os.WriteFile(filepath.Join(root, ".env"), []byte(env), 0644); err != nil {
+			ts.Fatal(err)
+		}
+		if err := RunMigrations(root, io.Discard, "up", "", false, WithMigrations(migrations)); err != nil {
+			ts.Fatalf("RunMigrations() up unexpected error: %v", err)
+		}
+		var out bytes.Buffer
+		if err := RunMigrations(root, &out, "version", "", false, WithMigrations(migrations)); err != nil {
+			ts.Fatalf("RunMigrations() version unexpected error: %v", err)
+		}
+		if out.String() != "Version 1\n" {
+			ts.Errorf("RunMigrations() version wrote %q, want version 1", out.String())
+		}
+	})
+}
+
+func TestCeleritas_MigratePlan(t *testing.T) {
+	withMigrations(t)
+	RegisterMigration(4, "backfill", func(ctx context.Context, tx *sql.Tx) error { return nil }, nil)
+	c, dsn := newMigrationApp(t)
+
+	versions := func(plan []PlannedMigration) string {
+		var s []string
+		for _, p := range plan {
+			s = append(s, fmt.Sprintf("%d %s", p.Version, p.Direction))
+		}
+		return strings.Join(s, ", ")
+	}
+
+	plan, err := c.MigratePlan(0)
+	if err != nil {
+		t.Fatalf("MigratePlan(0) unexpected error: %v", err)
+	}
+	if got := versions(plan); got != "1 up, 2 up, 3 up, 4 up" {
+		t.Errorf("MigratePlan(0) = %s, want every migration up", got)
+	}
+	if plan[1].File != "2_create_b.sqlite.up.sql" || plan[1].SQL != "create table b (id integer);" {
+		t.Errorf("MigratePlan(0)[1] = %+v, want the file and its SQL", plan[1])
+	}
+	if plan[3].File != "" || !strings.Contains(plan[3].SQL, "Go up function") {
+		t.Errorf("MigratePlan(0)[3] = %+v, want the Go migration described", plan[3])
+	}
+
+	// golang-migrate creates the version table when it connects; planning
+	// must not
+	var tables int
+	c.DB.Pool.QueryRow("select count(*) from sqlite_master where name = 'schema_migrations'").Scan(&tables)
+	if tables != 0 {
+		t.Fatal("MigratePlan() created the version table")
+	}
+
+	if err := c.Steps(2, dsn); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		steps int
+		want  string
+	}{
+		{1, "3 up"},
+		{-1, "2 down"},
+		{math.MinInt, "2 down, 1 down"},
+	}
+	for _, tt := range tests {
+		plan, err := c.MigratePlan(tt.steps)
+		if err != nil {
+			t.Fatalf("MigratePlan(%d) unexpected error: %v", tt.steps, err)
+		}
+		if got := versions(plan); got != tt.want {
+			t.Errorf("MigratePlan(%d) = %s, want %s", tt.steps, got, tt.want)
+		}
+	}
+
+	// the broken migration leaves the schema dirty
+	c.Steps(1, dsn)
+	if _, err := c.MigratePlan(0); err == nil || !strings.Contains(err.Error(), "dirty") {
+		t.Errorf("MigratePlan() error = %v, want the dirty schema reported", err)
+	}
+}
+
+func TestRunMigrations_dryRun(t *testing.T) {
+	c, _ := newMigrationApp(t)
+	env := "DATABASE_TYPE=sqlite\nDATABASE_PATH=app.db\n"
+	if err := os.WriteFile(filepath.Join(c.RootPath, ".env"), []byte(env), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err := RunMigrations(c.RootPath, &out, "steps", "2", true)
+	if !errors.Is(err, ErrPendingMigrations) {
+		t.Errorf("RunMigrations() error = %v, want ErrPendingMigrations", err)
+	}
+	want := "-- migration 1 create_a up: 1_create_a.up.sql\ncreate table a (id integer);\n\n" +
+		"-- migration 2 create_b.sqlite up: 2_create_b.sqlite.up.sql\ncreate table b (id integer);\n\n"
+	if out.String() != want {
+		t.Errorf("RunMigrations() wrote %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := RunMigrations(c.RootPath, &out, "down", "", true); err != nil {
+		t.Errorf("RunMigrations() down with nothing applied error = %v, want nil", err)
+	}
+	if out.String() != "No migrations to run\n" {
+		t.Errorf("RunMigrations() down wrote %q, want nothing to run", out.String())
+	}
+
+	if err := RunMigrations(c.RootPath, &out, "reset", "", true); err == nil {
+		t.Error("RunMigrations() reset --dry-run unexpected success")
+	}
+}
+
+func TestRunMigrations_dryRunFreshDatabase(t *testing.T) {
+	root := t.TempDir()
+	writeMigrations(t, root, map[string]string{"1_create_a.up.sql": "create table a (id integer);"})
+	env := "DATABASE_TYPE=sqlite\nDATABASE_PATH=app.db\n"
+	if err := os.WriteFile(filepath.Join(root, ".env"), []byte(env), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := RunMigrations(root, &out, "up", "", true); !errors.Is(err, ErrPendingMigrations) {
+		t.Errorf("RunMigrations() error = %v, want ErrPendingMigrations", err)
+	}
+	if !strings.HasPrefix(out.String(), "-- migration 1 create_a up") {
+		t.Errorf("RunMigrations() wrote %q, want migration 1 planned", out.String())
+	}
+	if _, err := os.Stat(filepath.Join(root, "app.db")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("RunMigrations() --dry-run created the database: %v", err)
+	}
+}
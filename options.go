@@ -0,0 +1,170 @@
+package celeritas
+
+import (
+	"database/sql"
+	"io/fs"
+	"log"
+	"log/slog"
+
+	"github.com/alexedwards/scs/v2"
+
+	"github.com/polyglotdev/celeritasproject/cache"
+)
+
+// options collects the settings passed to NewApp.
+type options struct {
+	rootPath     string
+	config       *Config
+	db           *sql.DB
+	replicas     []*sql.DB
+	cache        cache.Cacher
+	sessionStore scs.Store
+	logger       *slog.Logger
+	infoLog      *log.Logger
+	errorLog     *log.Logger
+	views        fs.FS
+	migrations   fs.FS
+}
+
+// Option configures an application created with NewApp.
+type Option func(*options)
+
+// WithRootPath sets the directory the application reads .env files, views
+// and migrations from. It defaults to the current working directory. Unlike
+// New, NewApp never creates folders or files under it.
+func WithRootPath(path string) Option {
+	return func(o *options) {
+		o.rootPath = path
+	}
+}
+
+// WithConfig supplies the configuration directly instead of loading it from
+// .env files and the environment. Start from DefaultConfig so that settings
+// you do not care about keep their usual defaults.
+func WithConfig(cfg Config) Option {
+	return func(o *options) {
+		o.config = &cfg
+	}
+}
+
+// WithDB uses an existing database pool instead of opening one from the
+// configuration. The Database.Type setting still selects the SQL dialect.
+// The caller owns the pool; it is not closed on shutdown.
+func WithDB(db *sql.DB) Option {
+	return func(o *options) {
+		o.db = db
+	}
+}
+
+// WithReplicas uses existing pools as read replicas of the primary instead of
+// opening them from DATABASE_REPLICA_HOSTS. The caller owns the pools; they
+// are health-checked but not closed on shutdown.
+func WithReplicas(dbs ...*sql.DB) Option {
+	return func(o *options) {
+		o.replicas = dbs
+	}
+}
+
+// WithCache uses the given cache instead of creating a Redis cache from the
+// configuration. The caller owns the cache; it is not closed on shutdown.
+func WithCache(c cache.Cacher) Option {
+	return func(o *options) {
+		o.cache = c
+	}
+}
+
+// WithSessionStore uses the given store for sessions instead of the store
+// selected by SESSION_TYPE.
+func WithSessionStore(store scs.Store) Option {
+	return func(o *options) {
+		o.sessionStore = store
+	}
+}
+
+// WithLogger uses the given structured logger instead of building one from
+// the LOG_* settings. Records logged with a request's context still carry its
+// request_id and user_id.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithLoggers uses the given loggers for InfoLog and ErrorLog instead of
+// adapters writing through the structured logger.
+//
+// Deprecated: Use WithLogger.
+func WithLoggers(infoLog, errorLog *log.Logger) Option {
+	return func(o *options) {
+		o.infoLog = infoLog
+		o.errorLog = errorLog
+	}
+}
+
+// WithViews reads templates from fsys, such as an embed.FS, instead of the
+// views folder under the root path.
+func WithViews(fsys fs.FS) Option {
+	return func(o *options) {
+		o.views = fsys
+	}
+}
+
+// WithMigrations reads migration files from fsys, such as an embed.FS,
+// instead of the migrations folder under the root path, so a compiled
+// application can migrate its database without the folder being deployed
+// alongside it. The files must be at the top of fsys; use fs.Sub to strip
+// the directory an embed.FS keeps:
+//
+//	//go:embed migrations
+//	var migrationFiles embed.FS
+//
+//	migrations, _ := fs.Sub(migrationFiles, "migrations")
+//	app, err := celeritas.NewApp(celeritas.WithMigrations(migrations))
+//
+// It sets the Migrations field, which an application created with New can
+// set itself before migrating; AUTO_MIGRATE runs during New, so it reads the
+// folder there. RunMigrations takes it as well.
+func WithMigrations(fsys fs.FS) Option {
+	return func(o *options) {
+		o.migrations = fsys
+	}
+}
+
+// DefaultConfig returns a Config with every default from the struct tags
+// applied and nothing read from the environment.
+func DefaultConfig() Config {
+	var cfg Config
+	decodeEnv(&cfg, func(string) (string, bool) { return "", false })
+	cfg.Debug = cfg.Env == EnvDevelopment
+	return cfg
+}
+
+// NewApp returns a new Celeritas application configured by opts. Unlike New,
+// it does not create folders or a .env file, and it reports every failure,
+// including a database that cannot be reached, as an error rather than
+// exiting the process. This makes it suitable for tests and for embedding
+// Celeritas in other programs.
+//
+// Without WithConfig, settings are loaded with LoadConfig from the root path.
+func NewApp(opts ...Option) (*Celeritas, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.config == nil {
+		cfg, err := LoadConfig(o.rootPath)
+		if err != nil {
+			return nil, err
+		}
+		o.config = &cfg
+	} else if problems := o.config.validate(); len(problems) > 0 {
+		return nil, &ConfigError{Problems: problems}
+	}
+
+	c := &Celeritas{}
+	if err := c.setup(o); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
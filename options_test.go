@@ -0,0 +1,134 @@
+package celeritas
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/alexedwards/scs/v2/memstore"
+)
+
+func TestNewApp(t *testing.T) {
+	views := fstest.MapFS{
+		"home.jet": &fstest.MapFile{Data: []byte("hello {{ .Port }}")},
+	}
+
+	t.Run("in-memory configuration and injected services", func(ts *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Port = "9999"
+		cfg.Database.Type = "postgres"
+		cfg.Database.User = "app"
+		cfg.Database.Name = "app"
+
+		// sql.Open does not connect, so this pool is usable without a server.
+		db, err := sql.Open("pgx", "host=localhost")
+		if err != nil {
+			ts.Fatal(err)
+		}
+		defer db.Close()
+
+		var infoBuf, errorBuf bytes.Buffer
+		store := memstore.New()
+
+		c, err := NewApp(
+			WithRootPath(ts.TempDir()),
+			WithConfig(cfg),
+			WithDB(db),
+			WithSessionStore(store),
+			WithLoggers(log.New(&infoBuf, "", 0), log.New(&errorBuf, "", 0)),
+			WithViews(views),
+		)
+		if err != nil {
+			ts.Fatalf("NewApp() unexpected error: %v", err)
+		}
+
+		if c.config.Port != "9999" {
+			ts.Errorf("NewApp() Port = %q, want 9999", c.config.Port)
+		}
+		if c.DB.Pool != db || c.DB.DataType != "postgres" {
+			ts.Errorf("NewApp() DB = %+v, want injected pool", c.DB)
+		}
+		if c.Session.Store != store {
+			ts.Error("NewApp() did not use the injected session store")
+		}
+		c.InfoLog.Print("info")
+		if infoBuf.String() != "info\n" {
+			ts.Errorf("NewApp() InfoLog wrote %q, want injected logger", infoBuf.String())
+		}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Session.LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := c.Render.JetPage(w, r, "home", nil, nil); err != nil {
+				ts.Errorf("JetPage() error: %v", err)
+			}
+		})).ServeHTTP(w, r)
+		if got := w.Body.String(); got != "hello 9999" {
+			ts.Errorf("JetPage() body = %q, want rendered view from fs.FS", got)
+		}
+	})
+
+	t.Run("does not create folders or files", func(ts *testing.T) {
+		root := ts.TempDir()
+		if _, err := NewApp(WithRootPath(root), WithConfig(DefaultConfig())); err != nil {
+			ts.Fatalf("NewApp() unexpected error: %v", err)
+		}
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			ts.Fatal(err)
+		}
+		if len(entries) != 0 {
+			ts.Errorf("NewApp() created %d entries in root path, want none", len(entries))
+		}
+	})
+
+	t.Run("loads configuration from root path", func(ts *testing.T) {
+		ts.Setenv("PORT", "")
+		root := ts.TempDir()
+		if err := os.WriteFile(filepath.Join(root, ".env"), []byte("PORT=7777\n"), 0644); err != nil {
+			ts.Fatal(err)
+		}
+
+		c, err := NewApp(WithRootPath(root))
+		if err != nil {
+			ts.Fatalf("NewApp() unexpected error: %v", err)
+		}
+		if c.config.Port != "7777" {
+			ts.Errorf("NewApp() Port = %q, want 7777", c.config.Port)
+		}
+	})
+
+	t.Run("invalid configuration returns error", func(ts *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Renderer = "pug"
+
+		_, err := NewApp(WithConfig(cfg))
+		var cfgErr *ConfigError
+		if !errors.As(err, &cfgErr) {
+			ts.Fatalf("NewApp() error = %v, want *ConfigError", err)
+		}
+	})
+
+	t.Run("unreachable database returns error", func(ts *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Database.Type = "pgx"
+		cfg.Database.Host = "127.0.0.1"
+		cfg.Database.Port = 1
+		cfg.Database.User = "app"
+		cfg.Database.Name = "app"
+		cfg.Database.RetryTimeout = 0
+
+		_, err := NewApp(WithConfig(cfg), WithLoggers(log.New(&bytes.Buffer{}, "", 0), log.New(&bytes.Buffer{}, "", 0)))
+		if err == nil || !strings.Contains(err.Error(), "connecting to pgx database") {
+			ts.Errorf("NewApp() error = %v, want database connection error", err)
+		}
+	})
+}
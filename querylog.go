@@ -0,0 +1,379 @@
+package celeritas
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueryLogConfig holds the settings for logging the SQL run on the pools
+// Celeritas opens.
+type QueryLogConfig struct {
+	// Enabled logs every query with its duration, arguments and the ID of
+	// the request that ran it. Queries run without a request context are
+	// logged without an ID.
+	Enabled bool `env:"DATABASE_LOG_QUERIES"`
+
+	// SlowThreshold logs queries that take at least this long as warnings.
+	// Zero disables slow-query detection.
+	SlowThreshold time.Duration `env:"DATABASE_SLOW_QUERY_THRESHOLD" default:"200ms"`
+
+	// Redact lists words that mark a query as handling secrets. Arguments
+	// named after one of them, and every positional argument of a query
+	// whose text contains one, are logged as [REDACTED].
+	Redact []string `env:"DATABASE_LOG_REDACT" default:"password,token,secret"`
+
+	// RepeatThreshold is how many times the same query may run during one
+	// request before it is reported as a likely N+1. Counting only happens
+	// in debug mode.
+	RepeatThreshold int `env:"DATABASE_QUERY_REPEAT_THRESHOLD" default:"5"`
+}
+
+// validate checks the query log settings.
+func (q QueryLogConfig) validate() []string {
+	if !q.Enabled {
+		return nil
+	}
+	var problems []string
+	if q.SlowThreshold < 0 {
+		problems = append(problems, fmt.Sprintf("DATABASE_SLOW_QUERY_THRESHOLD: must not be negative (got %s)", q.SlowThreshold))
+	}
+	if q.RepeatThreshold < 2 {
+		problems = append(problems, fmt.Sprintf("DATABASE_QUERY_REPEAT_THRESHOLD: must be at least 2 (got %d)", q.RepeatThreshold))
+	}
+	return problems
+}
+
+// sqlOpen opens a pool for dsn. With DATABASE_LOG_QUERIES set, connections
+// are made through a queryLogger wrapping the driver.
+func (c *Celeritas) sqlOpen(dbType, dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driverName(dbType), dsn)
+	if err != nil || !c.config.Database.QueryLog.Enabled {
+		return db, err
+	}
+
+	drv := db.Driver()
+	_ = db.Close()
+
+	var connector driver.Connector = dsnConnector{dsn: dsn, driver: drv}
+	if dc, ok := drv.(driver.DriverContext); ok {
+		if connector, err = dc.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+	return sql.OpenDB(&logConnector{
+		Connector: connector,
+		log:       &queryLogger{cfg: c.config.Database.QueryLog, logger: c.logger()},
+	}), nil
+}
+
+// dsnConnector adapts a driver without a connector of its own.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (d dsnConnector) Connect(context.Context) (driver.Conn, error) { return d.driver.Open(d.dsn) }
+func (d dsnConnector) Driver() driver.Driver                        { return d.driver }
+
+// queryLogger writes the log records for the instrumented connections.
+type queryLogger struct {
+	cfg    QueryLogConfig
+	logger *slog.Logger
+}
+
+// log records one query. Calls the driver skipped with driver.ErrSkip are
+// not logged, since database/sql runs them again through a statement.
+func (l *queryLogger) log(ctx context.Context, query string, args []driver.NamedValue, start time.Time, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+	elapsed := time.Since(start)
+	query = strings.Join(strings.Fields(query), " ")
+
+	if counts, ok := ctx.Value(queryCountsKey{}).(*queryCounts); ok {
+		counts.add(query)
+	}
+
+	level, msg := slog.LevelInfo, "query"
+	if l.cfg.SlowThreshold > 0 && elapsed >= l.cfg.SlowThreshold {
+		level, msg = slog.LevelWarn, "slow query"
+	}
+	if err != nil {
+		level = slog.LevelError
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("query", query),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+	if len(args) > 0 {
+		attrs = append(attrs, slog.Any("args", l.redact(query, args)))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// redact formats args for logging, hiding any that may hold a secret. For
+// positional arguments the query text is all there is to go on, so a query
+// that mentions a secret has all of its positional arguments hidden.
+func (l *queryLogger) redact(query string, args []driver.NamedValue) []string {
+	lower := strings.ToLower(query)
+	sensitive := func(s string) bool {
+		for _, word := range l.cfg.Redact {
+			if word != "" && strings.Contains(s, strings.ToLower(word)) {
+				return true
+			}
+		}
+		return false
+	}
+	secretQuery := sensitive(lower)
+
+	out := make([]string, len(args))
+	for i, arg := range args {
+		switch {
+		case arg.Name != "" && sensitive(strings.ToLower(arg.Name)):
+			out[i] = "[REDACTED]"
+		case arg.Name == "" && secretQuery:
+			out[i] = "[REDACTED]"
+		default:
+			out[i] = formatArg(arg.Value)
+		}
+	}
+	return out
+}
+
+// formatArg renders an argument compactly: binary data by size and long
+// strings truncated.
+func formatArg(v driver.Value) string {
+	const maxLen = 64
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return fmt.Sprintf("<%d bytes>", len(v))
+	case string:
+		if len(v) > maxLen {
+			return fmt.Sprintf("%q...", v[:maxLen])
+		}
+		return fmt.Sprintf("%q", v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// logConnector hands out connections that log their queries.
+type logConnector struct {
+	driver.Connector
+	log *queryLogger
+}
+
+func (c *logConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &logConn{Conn: conn, log: c.log}, nil
+}
+
+// logConn wraps a driver connection, logging queries run directly on it and
+// through the statements it prepares. Optional interfaces the underlying
+// connection does not implement report driver.ErrSkip, so database/sql falls
+// back exactly as it would without the wrapper.
+type logConn struct {
+	driver.Conn
+	log *queryLogger
+}
+
+func (c *logConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *logConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = pc.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &logStmt{Stmt: stmt, query: query, log: c.log}, nil
+}
+
+func (c *logConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	res, err := ec.ExecContext(ctx, query, args)
+	c.log.log(ctx, query, args, start, err)
+	return res, err
+}
+
+func (c *logConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := qc.QueryContext(ctx, query, args)
+	c.log.log(ctx, query, args, start, err)
+	return rows, err
+}
+
+func (c *logConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return bc.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, errors.New("celeritas: driver does not support transaction options")
+	}
+	return c.Conn.Begin()
+}
+
+func (c *logConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *logConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *logConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *logConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// logStmt wraps a prepared statement, logging each execution.
+type logStmt struct {
+	driver.Stmt
+	query string
+	log   *queryLogger
+}
+
+func (s *logStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var res driver.Result
+	var err error
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = ec.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			res, err = s.Stmt.Exec(values)
+		}
+	}
+	s.log.log(ctx, s.query, args, start, err)
+	return res, err
+}
+
+func (s *logStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			rows, err = s.Stmt.Query(values)
+		}
+	}
+	s.log.log(ctx, s.query, args, start, err)
+	return rows, err
+}
+
+func (s *logStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("celeritas: driver does not support named arguments")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+type queryCountsKey struct{}
+
+// queryCounts tallies the queries run while serving one request.
+type queryCounts struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (q *queryCounts) add(query string) {
+	q.mu.Lock()
+	q.counts[query]++
+	q.mu.Unlock()
+}
+
+// QueryCounter is middleware that counts the queries each request runs and
+// warns about any query repeated DATABASE_QUERY_REPEAT_THRESHOLD times or
+// more, the usual sign of loading related rows one at a time in a loop
+// (an N+1 query). Queries are only counted when handlers pass r.Context() to
+// the database. It is installed in debug mode when query logging is enabled.
+func (c *Celeritas) QueryCounter(next http.Handler) http.Handler {
+	threshold := c.config.Database.QueryLog.RepeatThreshold
+	logger := c.logger()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counts := &queryCounts{counts: make(map[string]int)}
+		r = r.WithContext(context.WithValue(r.Context(), queryCountsKey{}, counts))
+		next.ServeHTTP(w, r)
+
+		counts.mu.Lock()
+		defer counts.mu.Unlock()
+		queries := make([]string, 0, len(counts.counts))
+		for query, n := range counts.counts {
+			if n >= threshold {
+				queries = append(queries, query)
+			}
+		}
+		sort.Strings(queries)
+		for _, query := range queries {
+			logger.WarnContext(r.Context(), "query repeated during request, possible N+1",
+				"query", query, "count", counts.counts[query], "path", r.URL.Path)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package celeritas
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func newQueryLogApp(t *testing.T, configure func(cfg *Config)) (*Celeritas, *bytes.Buffer) {
+	t.Helper()
+	c, buf := newSQLiteApp(t, func(cfg *Config) {
+		cfg.Database.QueryLog.Enabled = true
+		cfg.Database.QueryLog.SlowThreshold = time.Hour
+		if configure != nil {
+			configure(cfg)
+		}
+	})
+
+	if _, err := c.DB.Pool.Exec(`create table users (id integer primary key, email text, password text)`); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	return c, buf
+}
+
+func TestQueryLog(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middleware.RequestIDKey, "req-42")
+
+	t.Run("logs queries with arguments", func(ts *testing.T) {
+		c, buf := newQueryLogApp(ts, nil)
+
+		var n int
+		err := c.DB.Pool.QueryRowContext(ctx, "select count(*)\n  from users where email = ?", "a@example.com").Scan(&n)
+		if err != nil {
+			ts.Fatal(err)
+		}
+		out := buf.String()
+		for _, want := range []string{
+			`msg=query`,
+			`query="select count(*) from users where email = ?"`,
+			`args="[\"a@example.com\"]"`,
+			`request_id=req-42`,
+			`duration_ms=`,
+		} {
+			if !strings.Contains(out, want) {
+				ts.Errorf("log = %q, want it to contain %q", out, want)
+			}
+		}
+	})
+
+	t.Run("redacts secrets", func(ts *testing.T) {
+		c, buf := newQueryLogApp(ts, nil)
+
+		if _, err := c.DB.Pool.ExecContext(ctx, `insert into users (email, password) values (?, ?)`, "a@example.com", "hunter2"); err != nil {
+			ts.Fatal(err)
+		}
+		if _, err := c.DB.Pool.ExecContext(ctx, `select :email, :api_token`,
+			sql.Named("email", "b@example.com"), sql.Named("api_token", "abc")); err != nil {
+			ts.Fatal(err)
+		}
+		out := buf.String()
+		if strings.Contains(out, "hunter2") || strings.Contains(out, "abc") {
+			ts.Errorf("log = %q, want secrets redacted", out)
+		}
+		if !strings.Contains(out, `b@example.com`) {
+			ts.Errorf("log = %q, want the non-secret named argument", out)
+		}
+	})
+
+	t.Run("flags slow queries", func(ts *testing.T) {
+		c, buf := newQueryLogApp(ts, func(cfg *Config) {
+			cfg.Database.QueryLog.SlowThreshold = time.Nanosecond
+		})
+
+		if _, err := c.DB.Pool.ExecContext(ctx, `select 1`); err != nil {
+			ts.Fatal(err)
+		}
+		if out := buf.String(); !strings.Contains(out, `level=WARN msg="slow query"`) {
+			ts.Errorf("log = %q, want a slow query warning", out)
+		}
+	})
+
+	t.Run("logs failed queries", func(ts *testing.T) {
+		c, buf := newQueryLogApp(ts, nil)
+
+		if _, err := c.DB.Pool.ExecContext(ctx, `select * from missing`); err == nil {
+			ts.Fatal("ExecContext() succeeded, want an error")
+		}
+		if out := buf.String(); !strings.Contains(out, "level=ERROR") || !strings.Contains(out, "missing") {
+			ts.Errorf("log = %q, want the failure logged", out)
+		}
+	})
+
+	t.Run("transactions and prepared statements", func(ts *testing.T) {
+		c, buf := newQueryLogApp(ts, nil)
+
+		err := c.DB.WithTx(ctx, nil, func(tx *sql.Tx) error {
+			stmt, err := tx.PrepareContext(ctx, `insert into users (email) values (?)`)
+			if err != nil {
+				return err
+			}
+			defer stmt.Close()
+			_, err = stmt.ExecContext(ctx, "c@example.com")
+			return err
+		})
+		if err != nil {
+			ts.Fatalf("WithTx() unexpected error: %v", err)
+		}
+		if out := buf.String(); !strings.Contains(out, `c@example.com`) {
+			ts.Errorf("log = %q, want the prepared statement logged", out)
+		}
+	})
+}
+
+func TestCeleritas_QueryCounter(t *testing.T) {
+	c, buf := newQueryLogApp(t, func(cfg *Config) {
+		cfg.Debug = true
+		cfg.Database.QueryLog.RepeatThreshold = 3
+	})
+
+	c.Routes.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+		for id := range 4 {
+			var n int
+			_ = c.DB.Pool.QueryRowContext(r.Context(), `select count(*) from users where id = ?`, id).Scan(&n)
+		}
+		_, _ = c.DB.Pool.ExecContext(r.Context(), `select 1`)
+	})
+	c.Routes.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	out := buf.String()
+	if !strings.Contains(out, "possible N+1") || !strings.Contains(out, "count=4") {
+		t.Errorf("log = %q, want the repeated query reported", out)
+	}
+	if strings.Count(out, "possible N+1") != 1 {
+		t.Errorf("log = %q, want only the repeated query reported", out)
+	}
+}
+
+func TestQueryLogConfig_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     QueryLogConfig
+		wantErr string
+	}{
+		{"disabled", QueryLogConfig{}, ""},
+		{"valid", QueryLogConfig{Enabled: true, SlowThreshold: time.Second, RepeatThreshold: 5}, ""},
+		{"negative threshold", QueryLogConfig{Enabled: true, SlowThreshold: -time.Second, RepeatThreshold: 5}, "DATABASE_SLOW_QUERY_THRESHOLD"},
+		{"repeat threshold", QueryLogConfig{Enabled: true, RepeatThreshold: 1}, "DATABASE_QUERY_REPEAT_THRESHOLD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			problems := strings.Join(tt.cfg.validate(), "\n")
+			if tt.wantErr == "" && problems != "" {
+				ts.Errorf("validate() = %q, want no problems", problems)
+			}
+			if tt.wantErr != "" && !strings.Contains(problems, tt.wantErr) {
+				ts.Errorf("validate() = %q, want %q", problems, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package render
+
+import (
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/CloudyKit/jet/v6"
+)
+
+// FSLoader is a jet.Loader that reads templates from an fs.FS, such as an
+// embed.FS compiled into the application binary.
+type FSLoader struct {
+	fsys fs.FS
+}
+
+// compile time check that we implement jet.Loader
+var _ jet.Loader = (*FSLoader)(nil)
+
+// NewFSLoader returns a jet.Loader that reads templates from fsys.
+func NewFSLoader(fsys fs.FS) *FSLoader {
+	return &FSLoader{fsys: fsys}
+}
+
+// Exists reports whether the template exists in the file system.
+func (l *FSLoader) Exists(templatePath string) bool {
+	info, err := fs.Stat(l.fsys, fsPath(templatePath))
+	return err == nil && !info.IsDir()
+}
+
+// Open returns the template's contents.
+func (l *FSLoader) Open(templatePath string) (io.ReadCloser, error) {
+	return l.fsys.Open(fsPath(templatePath))
+}
+
+// fsPath converts a jet template path, which always starts with a slash, to
+// the unrooted form fs.FS expects.
+func fsPath(templatePath string) string {
+	return strings.TrimPrefix(templatePath, "/")
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"log"
 	"net/http"
 	"strings"
@@ -21,6 +22,7 @@ type Render struct {
 	ServerName string
 	JetViews   *jet.Set
 	Session    *scs.SessionManager
+	Views      fs.FS // when set, Go templates are read from here instead of RootPath/views
 }
 
 type TemplateData struct {
@@ -60,7 +62,13 @@ func (c *Render) Page(w http.ResponseWriter, r *http.Request, view string, varia
 
 // GoPage renders a standard Go template
 func (c *Render) GoPage(w http.ResponseWriter, r *http.Request, view string, data interface{}) error {
-	tmpl, err := template.ParseFiles(fmt.Sprintf("%s/views/%s.page.tmpl", c.RootPath, view))
+	var tmpl *template.Template
+	var err error
+	if c.Views != nil {
+		tmpl, err = template.ParseFS(c.Views, fmt.Sprintf("%s.page.tmpl", view))
+	} else {
+		tmpl, err = template.ParseFiles(fmt.Sprintf("%s/views/%s.page.tmpl", c.RootPath, view))
+	}
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,305 @@
+package celeritas
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Replica selection policies for DATABASE_REPLICA_POLICY.
+const (
+	ReplicaRoundRobin       = "round-robin"
+	ReplicaLeastConnections = "least-connections"
+)
+
+// ReplicaConfig holds the settings for read replicas of the primary
+// database. Replicas share the primary's credentials, database name and pool
+// settings.
+type ReplicaConfig struct {
+	// Hosts lists the replicas as host or host:port. A host without a port
+	// uses DATABASE_PORT.
+	Hosts []string `env:"DATABASE_REPLICA_HOSTS"`
+
+	// Policy chooses between healthy replicas: round-robin or
+	// least-connections.
+	Policy string `env:"DATABASE_REPLICA_POLICY" default:"round-robin"`
+
+	// HealthInterval is how often each replica is pinged. A replica that
+	// fails its ping receives no reads until it answers again.
+	HealthInterval time.Duration `env:"DATABASE_REPLICA_HEALTH_INTERVAL" default:"10s"`
+}
+
+// validate checks the replica settings for the given database type.
+func (r ReplicaConfig) validate(dbType string) []string {
+	if len(r.Hosts) == 0 {
+		return nil
+	}
+	var problems []string
+	if dbType == "sqlite" {
+		problems = append(problems, "DATABASE_REPLICA_HOSTS: not supported when DATABASE_TYPE is sqlite")
+	}
+	if r.Policy != ReplicaRoundRobin && r.Policy != ReplicaLeastConnections {
+		problems = append(problems, fmt.Sprintf(
+			"DATABASE_REPLICA_POLICY: must be one of %s, %s (got %q)", ReplicaRoundRobin, ReplicaLeastConnections, r.Policy))
+	}
+	if r.HealthInterval <= 0 {
+		problems = append(problems, "DATABASE_REPLICA_HEALTH_INTERVAL: must be greater than zero")
+	}
+	return problems
+}
+
+// Writer returns the primary pool. All writes, and reads that must see them,
+// go through it.
+func (d Database) Writer() *sql.DB {
+	return d.Pool
+}
+
+// Reader returns a pool to run read-only queries on. It picks a healthy
+// replica according to DATABASE_REPLICA_POLICY, and falls back to the primary
+// when there are no replicas, none are healthy, or ctx was marked with
+// WithPrimary.
+func (d Database) Reader(ctx context.Context) *sql.DB {
+	if d.replicas == nil || usePrimary(ctx) {
+		return d.Pool
+	}
+	if db := d.replicas.pick(); db != nil {
+		return db
+	}
+	return d.Pool
+}
+
+type primaryKey struct{}
+
+// WithPrimary returns a context that makes Database.Reader return the
+// primary. Use it after a write whose result must be read back immediately,
+// before replication has caught up.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func usePrimary(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
+
+// replica is one read replica and its last known health.
+type replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// replicaSet selects between replicas and tracks their health.
+type replicaSet struct {
+	policy   string
+	replicas []*replica
+	next     atomic.Uint64
+	logger   *slog.Logger
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{} // nil until watch starts
+}
+
+func newReplicaSet(policy string, logger *slog.Logger) *replicaSet {
+	return &replicaSet{
+		policy: policy,
+		logger: logger,
+		stop:   make(chan struct{}),
+	}
+}
+
+// add registers db as a replica with its initial health.
+func (s *replicaSet) add(name string, db *sql.DB, healthy bool) {
+	r := &replica{name: name, db: db}
+	r.healthy.Store(healthy)
+	s.replicas = append(s.replicas, r)
+}
+
+// pick returns a healthy replica, or nil if there is none.
+func (s *replicaSet) pick() *sql.DB {
+	switch s.policy {
+	case ReplicaLeastConnections:
+		var best *replica
+		bestInUse := 0
+		for _, r := range s.replicas {
+			if !r.healthy.Load() {
+				continue
+			}
+			if inUse := r.db.Stats().InUse; best == nil || inUse < bestInUse {
+				best, bestInUse = r, inUse
+			}
+		}
+		if best == nil {
+			return nil
+		}
+		return best.db
+
+	default:
+		n := len(s.replicas)
+		start := int(s.next.Add(1)-1) % n
+		for i := range n {
+			if r := s.replicas[(start+i)%n]; r.healthy.Load() {
+				return r.db
+			}
+		}
+		return nil
+	}
+}
+
+// checkAll pings every replica and records whether it answered.
+func (s *replicaSet) checkAll(ctx context.Context, timeout time.Duration) {
+	var wg sync.WaitGroup
+	for _, r := range s.replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			err := r.db.PingContext(ctx)
+
+			healthy := err == nil
+			if r.healthy.Swap(healthy) != healthy {
+				if healthy {
+					s.logger.Info("database replica recovered", "replica", r.name)
+				} else {
+					s.logger.Warn("database replica unhealthy, reading from other replicas or the primary",
+						"replica", r.name, "error", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// watch starts pinging the replicas every interval until close is called.
+func (s *replicaSet) watch(interval time.Duration) {
+	s.done = make(chan struct{})
+	go s.run(interval)
+}
+
+func (s *replicaSet) run(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.stop
+		cancel()
+	}()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.checkAll(ctx, interval)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// close stops the health checks and, when owned is true, closes the pools.
+func (s *replicaSet) close(owned bool) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+	if s.done != nil {
+		<-s.done
+	}
+
+	if !owned {
+		return nil
+	}
+	var firstErr error
+	for _, r := range s.replicas {
+		if err := r.db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// setupReplicas attaches the read replicas to c.DB and starts their health
+// checks. Injected pools take precedence over DATABASE_REPLICA_HOSTS.
+func (c *Celeritas) setupReplicas(injected []*sql.DB) error {
+	if len(injected) == 0 && len(c.config.Database.Replicas.Hosts) == 0 {
+		return nil
+	}
+	if c.DB.Pool == nil {
+		return fmt.Errorf("read replicas require a primary database")
+	}
+
+	owned := len(injected) == 0
+	var set *replicaSet
+	if owned {
+		var err error
+		if set, err = c.openReplicas(); err != nil {
+			return err
+		}
+	} else {
+		set = newReplicaSet(c.config.Database.Replicas.Policy, c.logger())
+		for i, db := range injected {
+			set.add(fmt.Sprintf("replica-%d", i+1), db, true)
+		}
+	}
+
+	interval := c.config.Database.Replicas.HealthInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	set.watch(interval)
+	c.DB.replicas = set
+	c.OnShutdown("database replicas", func(ctx context.Context) error {
+		return set.close(owned)
+	})
+	return nil
+}
+
+// openReplicas opens a pool for every host in DATABASE_REPLICA_HOSTS. A
+// replica that cannot be reached at startup is not an error: it starts out
+// unhealthy and receives reads once its health check succeeds.
+func (c *Celeritas) openReplicas() (*replicaSet, error) {
+	cfg := c.config.Database
+	set := newReplicaSet(cfg.Replicas.Policy, c.logger())
+	// closes the pools opened for the hosts before the one that failed
+	fail := func(err error) (*replicaSet, error) {
+		_ = set.close(true)
+		return nil, err
+	}
+
+	for _, host := range cfg.Replicas.Hosts {
+		replicaCfg := cfg
+		replicaCfg.Host = host
+		if h, p, err := net.SplitHostPort(host); err == nil {
+			port, err := strconv.Atoi(p)
+			if err != nil {
+				return fail(fmt.Errorf("replica %s: invalid port %q", host, p))
+			}
+			replicaCfg.Host, replicaCfg.Port = h, port
+		}
+
+		db, err := c.sqlOpen(cfg.Type, replicaCfg.DSN())
+		if err != nil {
+			return fail(fmt.Errorf("replica %s: %w", host, err))
+		}
+		cfg.applyPool(db)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = db.PingContext(ctx)
+		cancel()
+		if err != nil {
+			c.logger().Warn("database replica unreachable at startup", "replica", host, "error", err)
+		}
+		set.add(host, db, err == nil)
+	}
+	return set, nil
+}
@@ -0,0 +1,158 @@
+package celeritas
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingDriver is a database driver that only counts the connections open
+// through it.
+type countingDriver struct{ open atomic.Int32 }
+
+func (d *countingDriver) Open(string) (driver.Conn, error) {
+	d.open.Add(1)
+	return countingConn{d}, nil
+}
+
+type countingConn struct{ d *countingDriver }
+
+func (c countingConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c countingConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+func (c countingConn) Close() error                        { c.d.open.Add(-1); return nil }
+
+var replicaDriver = &countingDriver{}
+
+func init() { sql.Register("replicatest", replicaDriver) }
+
+// openSQLite opens a throwaway SQLite database for replica tests.
+func openSQLite(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), name+".db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newReplicaApp(t *testing.T, policy string, primary *sql.DB, replicas ...*sql.DB) *Celeritas {
+	t.Helper()
+	c, _ := newSQLiteApp(t, func(cfg *Config) {
+		cfg.Database.Replicas.Policy = policy
+	}, WithDB(primary), WithReplicas(replicas...))
+	t.Cleanup(func() { _ = c.DB.replicas.close(false) })
+	return c
+}
+
+func TestDatabase_Reader(t *testing.T) {
+	primary := openSQLite(t, "primary")
+	r1, r2 := openSQLite(t, "r1"), openSQLite(t, "r2")
+	ctx := context.Background()
+
+	t.Run("round robin", func(ts *testing.T) {
+		c := newReplicaApp(ts, ReplicaRoundRobin, primary, r1, r2)
+		got := []*sql.DB{c.DB.Reader(ctx), c.DB.Reader(ctx), c.DB.Reader(ctx)}
+		if got[0] != r1 || got[1] != r2 || got[2] != r1 {
+			ts.Error("Reader() did not alternate between replicas")
+		}
+		if c.DB.Writer() != primary {
+			ts.Error("Writer() did not return the primary")
+		}
+	})
+
+	t.Run("least connections", func(ts *testing.T) {
+		c := newReplicaApp(ts, ReplicaLeastConnections, primary, r1, r2)
+
+		conn, err := r1.Conn(ctx)
+		if err != nil {
+			ts.Fatal(err)
+		}
+		defer conn.Close()
+
+		for range 3 {
+			if c.DB.Reader(ctx) != r2 {
+				ts.Fatal("Reader() did not pick the replica with fewer connections in use")
+			}
+		}
+	})
+
+	t.Run("force primary", func(ts *testing.T) {
+		c := newReplicaApp(ts, ReplicaRoundRobin, primary, r1, r2)
+		if c.DB.Reader(WithPrimary(ctx)) != primary {
+			ts.Error("Reader() with WithPrimary did not return the primary")
+		}
+	})
+
+	t.Run("unhealthy replicas fall back", func(ts *testing.T) {
+		down := openSQLite(ts, "down")
+		c := newReplicaApp(ts, ReplicaRoundRobin, primary, down, r2)
+
+		down.Close() // pings now fail
+		c.DB.replicas.checkAll(ctx, time.Second)
+		for range 3 {
+			if c.DB.Reader(ctx) != r2 {
+				ts.Fatal("Reader() returned an unhealthy replica")
+			}
+		}
+
+		c.DB.replicas.replicas[1].healthy.Store(false)
+		if c.DB.Reader(ctx) != primary {
+			ts.Error("Reader() with no healthy replicas did not fall back to the primary")
+		}
+	})
+
+	t.Run("no replicas", func(ts *testing.T) {
+		d := Database{Pool: primary}
+		if d.Reader(ctx) != primary {
+			ts.Error("Reader() without replicas did not return the primary")
+		}
+	})
+}
+
+func TestCeleritas_openReplicas_closesOnError(t *testing.T) {
+	c := &Celeritas{}
+	c.config.Database.Type = "replicatest"
+	c.config.Database.MaxIdle = 2
+	c.config.Database.Replicas.Hosts = []string{"replica1", "replica2:nope"}
+
+	set, err := c.openReplicas()
+	if set != nil || err == nil || !strings.Contains(err.Error(), "replica2:nope") {
+		t.Fatalf("openReplicas() = %v, %v, want the bad host reported", set, err)
+	}
+	if open := replicaDriver.open.Load(); open != 0 {
+		t.Errorf("openReplicas() left %d connections open to the good replica", open)
+	}
+}
+
+func TestReplicaConfig_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbType  string
+		cfg     ReplicaConfig
+		wantErr string
+	}{
+		{"no replicas", "postgres", ReplicaConfig{}, ""},
+		{"valid", "postgres", ReplicaConfig{Hosts: []string{"r1", "r2:5433"}, Policy: ReplicaLeastConnections, HealthInterval: time.Second}, ""},
+		{"bad policy", "mysql", ReplicaConfig{Hosts: []string{"r1"}, Policy: "random", HealthInterval: time.Second}, "DATABASE_REPLICA_POLICY"},
+		{"sqlite", "sqlite", ReplicaConfig{Hosts: []string{"r1"}, Policy: ReplicaRoundRobin, HealthInterval: time.Second}, "not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			problems := strings.Join(tt.cfg.validate(tt.dbType), "\n")
+			if tt.wantErr == "" && problems != "" {
+				ts.Errorf("validate() = %q, want no problems", problems)
+			}
+			if tt.wantErr != "" && !strings.Contains(problems, tt.wantErr) {
+				ts.Errorf("validate() = %q, want %q", problems, tt.wantErr)
+			}
+		})
+	}
+}
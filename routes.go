@@ -9,14 +9,57 @@ import (
 
 func (c *Celeritas) routes() http.Handler {
 	mux := chi.NewRouter()
-	mux.Use(middleware.RequestID)
-	mux.Use(middleware.RealIP)
-	if c.Debug {
-		mux.Use(middleware.Logger)
-	}
-	mux.Use(middleware.Recoverer)
-	mux.Use(c.SessionLoad)
-	mux.Use(c.NoSurf)
+	mux.Use(c.middleware()...)
 
 	return mux
 }
+
+// middleware returns the framework middleware every request passes through,
+// in the order it runs.
+func (c *Celeritas) middleware() []func(http.Handler) http.Handler {
+	mw := []func(http.Handler) http.Handler{middleware.RequestID, middleware.RealIP}
+	if c.config.TLS.Enabled() && c.config.TLS.HSTSMaxAge > 0 {
+		mw = append(mw, c.HSTS)
+	}
+	if c.config.AccessLog.Format != "" && c.config.AccessLog.Format != "off" {
+		mw = append(mw, c.AccessLog)
+	}
+	if c.config.Metrics.Enabled {
+		mw = append(mw, c.MetricsMiddleware)
+	}
+	if c.Debug && c.config.Database.QueryLog.Enabled {
+		mw = append(mw, c.QueryCounter)
+	}
+	return append(mw, middleware.Recoverer, c.SessionLoad, c.LogContext, c.NoSurf)
+}
+
+// Handler returns the handler the server runs. Requests for the built-in
+// health and metrics endpoints go to a router of their own, which has the same
+// framework middleware as Routes, and everything else goes to Routes.
+// Keeping the built-in endpoints off Routes leaves the application free to
+// add middleware to it after New, which chi refuses once a route exists.
+func (c *Celeritas) Handler() http.Handler {
+	endpoints := map[string]http.Handler{
+		c.config.Health.LivePath:  http.HandlerFunc(c.Liveness),
+		c.config.Health.ReadyPath: http.HandlerFunc(c.Readiness),
+	}
+	if c.config.Metrics.Enabled {
+		endpoints[c.config.Metrics.Path] = c.Metrics.Handler()
+	}
+
+	builtins := chi.NewRouter()
+	builtins.Use(c.middleware()...)
+	for path, h := range endpoints {
+		if path != "" {
+			builtins.Method(http.MethodGet, path, h)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if builtins.Match(chi.NewRouteContext(), r.Method, r.URL.Path) {
+			builtins.ServeHTTP(w, r)
+			return
+		}
+		c.Routes.ServeHTTP(w, r)
+	})
+}
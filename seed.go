@@ -0,0 +1,276 @@
+package celeritas
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Seeder fills the database with data. Seeders run in a transaction that is
+// rolled back if Seed returns an error.
+type Seeder interface {
+	Seed(ctx context.Context, tx *sql.Tx) error
+}
+
+// SeederFunc adapts a function to the Seeder interface.
+type SeederFunc func(ctx context.Context, tx *sql.Tx) error
+
+// Seed calls f(ctx, tx).
+func (f SeederFunc) Seed(ctx context.Context, tx *sql.Tx) error {
+	return f(ctx, tx)
+}
+
+var seederRegistry = struct {
+	mu      sync.Mutex
+	seeders map[string]Seeder
+}{seeders: make(map[string]Seeder)}
+
+// RegisterSeeder makes a Go seeder available to Seed under name. It is meant
+// to be called from the init function of the files `celeritas make seeder`
+// generates in the seeders package, and panics if name is registered twice.
+func RegisterSeeder(name string, s Seeder) {
+	seederRegistry.mu.Lock()
+	defer seederRegistry.mu.Unlock()
+	if s == nil {
+		panic("celeritas: RegisterSeeder seeder is nil")
+	}
+	if _, dup := seederRegistry.seeders[name]; dup {
+		panic("celeritas: RegisterSeeder called twice for seeder " + name)
+	}
+	seederRegistry.seeders[name] = s
+}
+
+// SeedOptions controls a Seed run.
+type SeedOptions struct {
+	// Names restricts the run to these seeders. A name matches a seeder
+	// either exactly or without its timestamp prefix, so users matches
+	// 1712345678_users. Empty runs every seeder.
+	Names []string
+
+	// Env is the application's APP_ENV. Seeding production is refused
+	// unless Force is set.
+	Env   string
+	Force bool
+}
+
+// seedsTable records the seeders that have run, so running Seed again only
+// runs new ones.
+const seedsTable = "seeds"
+
+// timestampPrefix matches the prefix make seeder gives seeder names.
+var timestampPrefix = regexp.MustCompile(`^\d+_`)
+
+// Seed runs the SQL seeders in dir and the registered Go seeders, in name
+// order, skipping any already recorded in the seeds table. Each seeder runs
+// and is recorded in its own transaction. It returns the names of the
+// seeders it ran.
+//
+// SQL seeders are the files in dir ending in .sql. On MySQL and MariaDB,
+// whose driver runs one statement per call, a file is split into statements
+// at semicolons that end a line.
+func Seed(ctx context.Context, db Database, dir string, opts SeedOptions) ([]string, error) {
+	if db.Pool == nil {
+		return nil, errors.New("no database connection")
+	}
+	if opts.Env == EnvProduction && !opts.Force {
+		return nil, errors.New("refusing to seed the production database without --force")
+	}
+
+	seeders, err := loadSeeders(db.DataType, dir)
+	if err != nil {
+		return nil, err
+	}
+	names, err := selectSeeders(seeders, opts.Names)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Pool.ExecContext(ctx, `create table if not exists `+seedsTable+
+		` (name varchar(255) not null primary key, seeded_at timestamp not null default current_timestamp)`); err != nil {
+		return nil, fmt.Errorf("creating %s table: %w", seedsTable, err)
+	}
+	done, err := seededNames(ctx, db.Pool)
+	if err != nil {
+		return nil, err
+	}
+
+	record := `insert into ` + seedsTable + ` (name) values (?)`
+	if driverName(db.DataType) == "pgx" {
+		record = `insert into ` + seedsTable + ` (name) values ($1)`
+	}
+
+	var ran []string
+	for _, name := range names {
+		if done[name] {
+			continue
+		}
+		err := db.WithTx(ctx, nil, func(tx *sql.Tx) error {
+			if err := seeders[name].Seed(ctx, tx); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, record, name)
+			return err
+		})
+		if err != nil {
+			return ran, fmt.Errorf("seeder %s: %w", name, err)
+		}
+		ran = append(ran, name)
+	}
+	return ran, nil
+}
+
+// loadSeeders collects the registered Go seeders and the SQL files in dir. A
+// missing dir just means there are no SQL seeders.
+func loadSeeders(dbType, dir string) (map[string]Seeder, error) {
+	seeders := make(map[string]Seeder)
+	seederRegistry.mu.Lock()
+	for name, s := range seederRegistry.seeders {
+		seeders[name] = s
+	}
+	seederRegistry.mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".sql")
+		if _, dup := seeders[name]; dup {
+			return nil, fmt.Errorf("seeder %s is defined both in Go and in %s", name, file)
+		}
+		seeders[name] = sqlSeeder{file: file, split: driverName(dbType) == "mysql"}
+	}
+	return seeders, nil
+}
+
+// selectSeeders returns the names of the seeders to run, in order.
+func selectSeeders(seeders map[string]Seeder, want []string) ([]string, error) {
+	all := make([]string, 0, len(seeders))
+	for name := range seeders {
+		all = append(all, name)
+	}
+	slices.Sort(all)
+	if len(want) == 0 {
+		return all, nil
+	}
+
+	var names []string
+	for _, w := range want {
+		found := false
+		for _, name := range all {
+			if name == w || timestampPrefix.ReplaceAllString(name, "") == w {
+				if !slices.Contains(names, name) {
+					names = append(names, name)
+				}
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no seeder named %s", w)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// seededNames returns the seeders recorded in the seeds table.
+func seededNames(ctx context.Context, db *sql.DB) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, `select name from `+seedsTable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		done[name] = true
+	}
+	return done, rows.Err()
+}
+
+// sqlSeeder runs the statements in a .sql file.
+type sqlSeeder struct {
+	file  string
+	split bool
+}
+
+func (s sqlSeeder) Seed(ctx context.Context, tx *sql.Tx) error {
+	data, err := os.ReadFile(s.file)
+	if err != nil {
+		return err
+	}
+
+	statements := []string{string(data)}
+	if s.split {
+		statements = splitStatements(string(data))
+	}
+	for _, stmt := range statements {
+		if blankSQL(stmt) {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements splits src at semicolons that end a line.
+func splitStatements(src string) []string {
+	var statements []string
+	var current strings.Builder
+	for line := range strings.Lines(src) {
+		current.WriteString(line)
+		if strings.HasSuffix(strings.TrimSpace(line), ";") {
+			statements = append(statements, current.String())
+			current.Reset()
+		}
+	}
+	return append(statements, current.String())
+}
+
+// blankSQL reports whether src holds nothing but whitespace and -- comments,
+// which some drivers reject as an empty query.
+func blankSQL(src string) bool {
+	for line := range strings.Lines(src) {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
+
+// RunSeeders loads the configuration in rootPath, connects to the database
+// and runs Seed with the seeders directory under rootPath. It is what
+// `celeritas db seed` runs.
+func RunSeeders(ctx context.Context, rootPath string, opts SeedOptions) ([]string, error) {
+	cfg, err := LoadConfig(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Database.Type == "" {
+		return nil, errors.New("DATABASE_TYPE is not set")
+	}
+
+	var c Celeritas
+	pool, err := c.OpenDB(cfg.Database.Type, cfg.Database.withRoot(rootPath).DSN())
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s database: %w", cfg.Database.Type, err)
+	}
+	defer pool.Close()
+
+	opts.Env = cfg.Env
+	return Seed(ctx, Database{DataType: cfg.Database.Type, Pool: pool}, filepath.Join(rootPath, "seeders"), opts)
+}
@@ -0,0 +1,147 @@
+package celeritas
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// withSeeders replaces the registered Go seeders for the duration of a test.
+func withSeeders(t *testing.T, seeders map[string]Seeder) {
+	t.Helper()
+	seederRegistry.mu.Lock()
+	saved := seederRegistry.seeders
+	seederRegistry.seeders = make(map[string]Seeder)
+	seederRegistry.mu.Unlock()
+	t.Cleanup(func() {
+		seederRegistry.mu.Lock()
+		seederRegistry.seeders = saved
+		seederRegistry.mu.Unlock()
+	})
+
+	for name, s := range seeders {
+		RegisterSeeder(name, s)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	ctx := context.Background()
+	insertGo := SeederFunc(func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `insert into items (name) values ('go')`)
+		return err
+	})
+
+	setup := func(t *testing.T) (Database, string) {
+		d := newTxDatabase(t)
+		dir := t.TempDir()
+		files := map[string]string{
+			"1_first.sql":  "insert into items (name) values ('one');\ninsert into items (name) values ('two');\n",
+			"3_third.sql":  "-- the third\ninsert into items (name) values ('three');\n",
+			"3_blank.sql":  "-- nothing here yet\n",
+			"notes.txt":    "not a seeder",
+			"4_broken.sql": "insert into missing values (1);\n",
+		}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		withSeeders(t, map[string]Seeder{"2_second": insertGo})
+		return d, dir
+	}
+
+	t.Run("runs in order once", func(ts *testing.T) {
+		d, dir := setup(ts)
+
+		ran, err := Seed(ctx, d, dir, SeedOptions{Names: []string{"first", "second", "3_third"}})
+		if err != nil {
+			ts.Fatalf("Seed() unexpected error: %v", err)
+		}
+		if want := []string{"1_first", "2_second", "3_third"}; !slices.Equal(ran, want) {
+			ts.Errorf("Seed() ran %v, want %v", ran, want)
+		}
+		if got := countItems(ts, d); got != 4 {
+			ts.Errorf("Seed() inserted %d rows, want 4", got)
+		}
+
+		ran, err = Seed(ctx, d, dir, SeedOptions{Names: []string{"first"}})
+		if err != nil || len(ran) != 0 {
+			ts.Errorf("Seed() again = %v, %v, want nothing run", ran, err)
+		}
+	})
+
+	t.Run("failing seeder is rolled back", func(ts *testing.T) {
+		d, dir := setup(ts)
+
+		ran, err := Seed(ctx, d, dir, SeedOptions{})
+		if err == nil || !strings.Contains(err.Error(), "seeder 4_broken") {
+			ts.Fatalf("Seed() error = %v, want seeder 4_broken to fail", err)
+		}
+		if len(ran) != 4 {
+			ts.Errorf("Seed() ran %v before failing, want the first four", ran)
+		}
+
+		var recorded int
+		if err := d.Pool.QueryRow(`select count(*) from seeds where name = '4_broken'`).Scan(&recorded); err != nil {
+			ts.Fatal(err)
+		}
+		if recorded != 0 {
+			ts.Error("Seed() recorded the failed seeder")
+		}
+	})
+
+	t.Run("unknown seeder", func(ts *testing.T) {
+		d, dir := setup(ts)
+		if _, err := Seed(ctx, d, dir, SeedOptions{Names: []string{"nope"}}); err == nil {
+			ts.Error("Seed() unexpected success for an unknown seeder")
+		}
+	})
+
+	t.Run("production requires force", func(ts *testing.T) {
+		d, dir := setup(ts)
+
+		_, err := Seed(ctx, d, dir, SeedOptions{Env: EnvProduction, Names: []string{"first"}})
+		if err == nil || !strings.Contains(err.Error(), "--force") {
+			ts.Errorf("Seed() error = %v, want a production refusal", err)
+		}
+		if _, err := Seed(ctx, d, dir, SeedOptions{Env: EnvProduction, Force: true, Names: []string{"first"}}); err != nil {
+			ts.Errorf("Seed() with Force unexpected error: %v", err)
+		}
+	})
+
+	t.Run("seeder in Go and SQL", func(ts *testing.T) {
+		d, dir := setup(ts)
+		if err := os.WriteFile(filepath.Join(dir, "2_second.sql"), nil, 0644); err != nil {
+			ts.Fatal(err)
+		}
+		if _, err := Seed(ctx, d, dir, SeedOptions{}); err == nil {
+			ts.Error("Seed() unexpected success with a seeder defined twice")
+		}
+	})
+}
+
+func TestRegisterSeeder(t *testing.T) {
+	withSeeders(t, nil)
+	RegisterSeeder("users", SeederFunc(func(context.Context, *sql.Tx) error { return errors.New("unused") }))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterSeeder() did not panic on a duplicate name")
+		}
+	}()
+	RegisterSeeder("users", SeederFunc(func(context.Context, *sql.Tx) error { return nil }))
+}
+
+func TestSplitStatements(t *testing.T) {
+	src := "insert into a values (1);\ninsert into b\n  values ('x;y');\n\n-- trailing comment\n"
+	got := splitStatements(src)
+	want := []string{"insert into a values (1);\n", "insert into b\n  values ('x;y');\n", "\n-- trailing comment\n"}
+	if !slices.Equal(got, want) {
+		t.Errorf("splitStatements() = %q, want %q", got, want)
+	}
+}
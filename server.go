@@ -0,0 +1,124 @@
+package celeritas
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ServerConfig holds the settings for the HTTP server and the socket it
+// listens on. A timeout of zero disables it, which long-lived responses such
+// as server-sent events may need.
+type ServerConfig struct {
+	// Host is the address to bind; empty listens on every interface.
+	Host string `env:"SERVER_HOST"`
+
+	// Socket, when set, listens on a Unix-domain socket at this path instead
+	// of Host and PORT. SocketMode sets its permissions, in octal.
+	Socket     string `env:"SERVER_SOCKET"`
+	SocketMode string `env:"SERVER_SOCKET_MODE" default:"0660"`
+
+	ReadTimeout       time.Duration `env:"SERVER_READ_TIMEOUT" default:"30s"`
+	ReadHeaderTimeout time.Duration `env:"SERVER_READ_HEADER_TIMEOUT" default:"10s"`
+	WriteTimeout      time.Duration `env:"SERVER_WRITE_TIMEOUT" default:"10m"`
+	IdleTimeout       time.Duration `env:"SERVER_IDLE_TIMEOUT" default:"30s"`
+	MaxHeaderBytes    int           `env:"SERVER_MAX_HEADER_BYTES" default:"1048576"`
+}
+
+// validate checks the server settings.
+func (s ServerConfig) validate() []string {
+	var problems []string
+	if _, err := strconv.ParseUint(s.SocketMode, 8, 32); err != nil {
+		problems = append(problems, fmt.Sprintf("SERVER_SOCKET_MODE: must be an octal file mode such as 0660 (got %q)", s.SocketMode))
+	}
+	for _, timeout := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"SERVER_READ_TIMEOUT", s.ReadTimeout},
+		{"SERVER_READ_HEADER_TIMEOUT", s.ReadHeaderTimeout},
+		{"SERVER_WRITE_TIMEOUT", s.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", s.IdleTimeout},
+	} {
+		if timeout.value < 0 {
+			problems = append(problems, fmt.Sprintf("%s: must not be negative (got %s)", timeout.key, timeout.value))
+		}
+	}
+	if s.MaxHeaderBytes < 0 {
+		problems = append(problems, fmt.Sprintf("SERVER_MAX_HEADER_BYTES: must not be negative (got %d)", s.MaxHeaderBytes))
+	}
+	return problems
+}
+
+// listenFDsStart is the first file descriptor passed by systemd socket
+// activation; 0, 1 and 2 are stdin, stdout and stderr.
+const listenFDsStart = 3
+
+// systemdListener returns the first socket passed by systemd socket
+// activation, or nil when the process was not socket-activated. The
+// LISTEN_* variables are cleared so child processes do not inherit them.
+func systemdListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return nil, nil
+	}
+
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	f := os.NewFile(uintptr(listenFDsStart), "systemd-socket")
+	defer f.Close() // net.FileListener duplicates the descriptor
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("using systemd socket: %w", err)
+	}
+	return ln, nil
+}
+
+// listen opens the listener for the main server. In order of precedence it
+// uses a socket passed by systemd (LISTEN_FDS), the Unix-domain socket at
+// SERVER_SOCKET, or a TCP socket on SERVER_HOST and PORT.
+func (c *Celeritas) listen() (net.Listener, error) {
+	ln, err := systemdListener()
+	if err != nil || ln != nil {
+		return ln, err
+	}
+
+	if path := c.config.Server.Socket; path != "" {
+		// a socket left behind by an unclean exit would make Listen fail,
+		// but anything else at the path is left alone in case it is a
+		// mistyped path to a file that matters
+		if fi, err := os.Lstat(path); err == nil {
+			if fi.Mode()&os.ModeSocket == 0 {
+				return nil, fmt.Errorf("SERVER_SOCKET %s exists and is not a socket", path)
+			}
+			if err := os.Remove(path); err != nil {
+				return nil, fmt.Errorf("removing stale socket: %w", err)
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+		ln, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, err
+		}
+		mode, err := strconv.ParseUint(c.config.Server.SocketMode, 8, 32)
+		if err == nil {
+			err = os.Chmod(path, os.FileMode(mode))
+		}
+		if err != nil {
+			_ = ln.Close()
+			return nil, fmt.Errorf("setting socket permissions: %w", err)
+		}
+		return ln, nil
+	}
+
+	return net.Listen("tcp", net.JoinHostPort(c.config.Server.Host, c.config.Port))
+}
@@ -0,0 +1,188 @@
+package celeritas
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerConfig_validate(t *testing.T) {
+	valid := DefaultConfig().Server
+
+	tests := []struct {
+		name    string
+		modify  func(*ServerConfig)
+		wantErr string
+	}{
+		{"defaults", func(*ServerConfig) {}, ""},
+		{"zero disables timeouts", func(s *ServerConfig) { s.WriteTimeout = 0 }, ""},
+		{"bad socket mode", func(s *ServerConfig) { s.SocketMode = "rw-rw----" }, "SERVER_SOCKET_MODE"},
+		{"negative timeout", func(s *ServerConfig) { s.IdleTimeout = -time.Second }, "SERVER_IDLE_TIMEOUT"},
+		{"negative header bytes", func(s *ServerConfig) { s.MaxHeaderBytes = -1 }, "SERVER_MAX_HEADER_BYTES"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			problems := strings.Join(cfg.validate(), "\n")
+			if tt.wantErr == "" && problems != "" {
+				ts.Errorf("validate() = %q, want no problems", problems)
+			}
+			if tt.wantErr != "" && !strings.Contains(problems, tt.wantErr) {
+				ts.Errorf("validate() = %q, want %q", problems, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCeleritas_createServer_Settings(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Port = "8080"
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.ReadHeaderTimeout = 2 * time.Second
+	cfg.Server.WriteTimeout = 0
+	cfg.Server.MaxHeaderBytes = 4096
+
+	c, err := NewApp(WithRootPath(t.TempDir()), WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("NewApp() unexpected error: %v", err)
+	}
+	srv, err := c.createServer()
+	if err != nil {
+		t.Fatalf("createServer() unexpected error: %v", err)
+	}
+
+	if srv.Addr != "127.0.0.1:8080" {
+		t.Errorf("createServer() Addr = %q, want 127.0.0.1:8080", srv.Addr)
+	}
+	if srv.ReadTimeout != 30*time.Second || srv.ReadHeaderTimeout != 2*time.Second ||
+		srv.WriteTimeout != 0 || srv.IdleTimeout != 30*time.Second {
+		t.Errorf("createServer() timeouts = read %s, header %s, write %s, idle %s",
+			srv.ReadTimeout, srv.ReadHeaderTimeout, srv.WriteTimeout, srv.IdleTimeout)
+	}
+	if srv.MaxHeaderBytes != 4096 {
+		t.Errorf("createServer() MaxHeaderBytes = %d, want 4096", srv.MaxHeaderBytes)
+	}
+}
+
+func TestCeleritas_ListenAndServe_UnixSocket(t *testing.T) {
+	// socket paths are limited to about 100 bytes, which t.TempDir can exceed
+	dir, err := os.MkdirTemp("", "cel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "app.sock")
+
+	// a stale socket from a previous run must not prevent startup
+	stale, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	cfg := DefaultConfig()
+	cfg.Port = ""
+	cfg.Server.Socket = socket
+	cfg.Server.SocketMode = "0600"
+
+	c, err := NewApp(WithRootPath(t.TempDir()), WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("NewApp() unexpected error: %v", err)
+	}
+	c.Routes.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("over unix"))
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- c.ListenAndServe() }()
+	defer func() {
+		c.Stop()
+		if err := <-done; err != nil {
+			t.Errorf("ListenAndServe() error = %v", err)
+		}
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+
+	var resp *http.Response
+	for range 50 {
+		if resp, err = client.Get("http://unix/"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("GET over unix socket: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "over unix" {
+		t.Errorf("GET over unix socket body = %q, want %q", body, "over unix")
+	}
+
+	info, err := os.Stat(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("socket mode = %o, want 600", info.Mode().Perm())
+	}
+}
+
+func TestCeleritas_listen_SocketPathNotSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.db")
+	if err := os.WriteFile(path, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Celeritas{}
+	c.config.Server.Socket = path
+	c.config.Server.SocketMode = "0600"
+	if ln, err := c.listen(); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		if ln != nil {
+			ln.Close()
+		}
+		t.Errorf("listen() error = %v, want a refusal to replace the file", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "data" {
+		t.Errorf("file at SERVER_SOCKET = %q, %v, want it left alone", data, err)
+	}
+}
+
+func TestSystemdListener_NotActivated(t *testing.T) {
+	tests := []struct {
+		name string
+		pid  string
+		fds  string
+	}{
+		{"no variables", "", ""},
+		{"other process", strconv.Itoa(os.Getpid() + 1), "1"},
+		{"no descriptors", strconv.Itoa(os.Getpid()), "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			ts.Setenv("LISTEN_PID", tt.pid)
+			ts.Setenv("LISTEN_FDS", tt.fds)
+
+			ln, err := systemdListener()
+			if ln != nil || err != nil {
+				ts.Errorf("systemdListener() = %v, %v, want nil, nil", ln, err)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/alexedwards/scs/mysqlstore"
 	"github.com/alexedwards/scs/postgresstore"
 	"github.com/alexedwards/scs/redisstore"
+	"github.com/alexedwards/scs/sqlite3store"
 	"github.com/alexedwards/scs/v2"
 	"github.com/gomodule/redigo/redis"
 )
@@ -37,7 +38,8 @@ type Session struct {
 	CookieDomain string
 
 	// SessionType determines the storage backend for sessions.
-	// Valid values are "redis", "mysql", "mariadb", "postgres", "postgresql".
+	// Valid values are "redis", "mysql", "mariadb", "postgres", "postgresql",
+	// "sqlite".
 	// Any other value defaults to cookie-based sessions.
 	SessionType string
 
@@ -93,6 +95,9 @@ func (c *Session) InitSession() *scs.SessionManager {
 	case "postgres", "postgresql":
 		session.Store = postgresstore.New(c.DBPool)
 
+	case "sqlite":
+		session.Store = sqlite3store.New(c.DBPool)
+
 	default:
 		// Default to cookie-based session store
 	}
@@ -0,0 +1,162 @@
+package celeritas
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// copyMigration copies a CLI migration template into dir under name.
+func copyMigration(t *testing.T, dir, template, name string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("cmd", "cli", "templates", "migrations", template))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// startSQLiteApp starts an application rooted in root on a SQLite database,
+// app.db, logging to the returned buffer. configure, when not nil, adjusts the
+// configuration, and opts are applied after the root, configuration and
+// logger. The database is closed when the test ends unless opts supplied it.
+func startSQLiteApp(t *testing.T, root string, configure func(cfg *Config), opts ...Option) (*Celeritas, *bytes.Buffer, error) {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Database.Type = "sqlite"
+	cfg.Database.Path = "app.db"
+	if configure != nil {
+		configure(&cfg)
+	}
+
+	var buf bytes.Buffer
+	opts = append([]Option{
+		WithRootPath(root),
+		WithConfig(cfg),
+		WithLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+	}, opts...)
+	c, err := NewApp(opts...)
+	if err != nil {
+		return nil, &buf, err
+	}
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if pool := c.DB.Pool; o.db == nil {
+		t.Cleanup(func() { pool.Close() })
+	}
+	return c, &buf, nil
+}
+
+// newSQLiteApp is startSQLiteApp in a temporary directory, failing the test if
+// the application does not start.
+func newSQLiteApp(t *testing.T, configure func(cfg *Config), opts ...Option) (*Celeritas, *bytes.Buffer) {
+	t.Helper()
+	c, logged, err := startSQLiteApp(t, t.TempDir(), configure, opts...)
+	if err != nil {
+		t.Fatalf("NewApp() unexpected error: %v", err)
+	}
+	return c, logged
+}
+
+// writeMigrations writes files to the migrations folder under root.
+func writeMigrations(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	dir := filepath.Join(root, "migrations")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCeleritas_SQLite(t *testing.T) {
+	root := t.TempDir()
+	migrations := filepath.Join(root, "migrations")
+	if err := os.Mkdir(migrations, 0755); err != nil {
+		t.Fatal(err)
+	}
+	copyMigration(t, migrations, "auth_tables.sqlite.sql", "1_create_auth_tables.up.sql")
+	copyMigration(t, migrations, "sqlite_session.sql", "2_create_sessions_table.up.sql")
+
+	cfg := DefaultConfig()
+	cfg.Database.Type = "sqlite"
+	cfg.Database.Path = "app.db"
+	cfg.SessionType = "sqlite"
+
+	c, err := NewApp(
+		WithRootPath(root),
+		WithConfig(cfg),
+		WithLogger(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))),
+	)
+	if err != nil {
+		t.Fatalf("NewApp() unexpected error: %v", err)
+	}
+	defer c.DB.Pool.Close()
+
+	if _, err := os.Stat(filepath.Join(root, "app.db")); err != nil {
+		t.Errorf("database file not created under the root path: %v", err)
+	}
+
+	if err := c.MigrateUp(c.config.Database.withRoot(root).MigrateURL()); err != nil {
+		t.Fatalf("MigrateUp() unexpected error: %v", err)
+	}
+
+	// the auth tables accept a user and enforce the foreign key
+	if _, err := c.DB.Pool.Exec(`insert into users (first_name, last_name, email, password) values ('a', 'b', 'a@example.com', 'x')`); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if _, err := c.DB.Pool.Exec(`insert into remember_tokens (user_id, remember_token) values (99, 't')`); err == nil {
+		t.Error("insert remember token for missing user succeeded, want foreign key error")
+	}
+
+	// sessions round-trip through the sqlite store
+	w := httptest.NewRecorder()
+	c.Session.LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Session.Put(r.Context(), "userID", 7)
+	})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) == 0 {
+		t.Fatal("LoadAndSave() did not set a session cookie")
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookies[0])
+	var got int
+	c.Session.LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = c.Session.GetInt(r.Context(), "userID")
+	})).ServeHTTP(httptest.NewRecorder(), r)
+	if got != 7 {
+		t.Errorf("session userID = %d, want 7", got)
+	}
+}
+
+func TestDatabaseConfig_SQLite(t *testing.T) {
+	cfg := DatabaseConfig{Type: "sqlite", Path: "db/app.db"}
+
+	if got, want := cfg.withRoot("/srv/app").DSN(),
+		"file:/srv/app/db/app.db?_pragma=foreign_keys%281%29&_pragma=journal_mode%28WAL%29&_pragma=busy_timeout%285000%29"; got != want {
+		t.Errorf("DSN() = %q, want %q", got, want)
+	}
+	if got, want := cfg.withRoot("/srv/app").MigrateURL(),
+		"sqlite:///srv/app/db/app.db?_pragma=foreign_keys%281%29&_pragma=journal_mode%28WAL%29&_pragma=busy_timeout%285000%29"; got != want {
+		t.Errorf("MigrateURL() = %q, want %q", got, want)
+	}
+
+	memory := DatabaseConfig{Type: "sqlite", Path: ":memory:"}
+	if got, want := memory.withRoot("/srv/app").DSN(),
+		"file::memory:?_pragma=foreign_keys%281%29&_pragma=busy_timeout%285000%29"; got != want {
+		t.Errorf("DSN() in memory = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,204 @@
+package celeritas
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// TLSConfig holds the settings for serving HTTPS. TLS is enabled when
+// CertFile is set.
+type TLSConfig struct {
+	CertFile string `env:"TLS_CERT_FILE"`
+	KeyFile  string `env:"TLS_KEY_FILE"`
+
+	// MinVersion is the lowest protocol version accepted: 1.0, 1.1, 1.2
+	// or 1.3.
+	MinVersion string `env:"TLS_MIN_VERSION" default:"1.2"`
+
+	// CipherSuites restricts the TLS 1.0-1.2 cipher suites to the named
+	// ones, for example TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. Empty uses Go's
+	// defaults. TLS 1.3 suites are not configurable.
+	CipherSuites []string `env:"TLS_CIPHER_SUITES"`
+
+	// RedirectPort, when set, starts a second plain-HTTP listener on that
+	// port which redirects every request to HTTPS.
+	RedirectPort string `env:"TLS_REDIRECT_PORT"`
+
+	// HSTSMaxAge is sent in the Strict-Transport-Security header of HTTPS
+	// responses. Zero disables the header.
+	HSTSMaxAge            time.Duration `env:"HSTS_MAX_AGE" default:"8760h"`
+	HSTSIncludeSubdomains bool          `env:"HSTS_INCLUDE_SUBDOMAINS"`
+	HSTSPreload           bool          `env:"HSTS_PRELOAD"`
+}
+
+// Enabled reports whether the server should serve HTTPS.
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != ""
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// validate checks the TLS settings.
+func (t TLSConfig) validate() []string {
+	var problems []string
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		problems = append(problems, "TLS_CERT_FILE, TLS_KEY_FILE: must be set together")
+	}
+	if _, ok := tlsVersions[t.MinVersion]; !ok {
+		problems = append(problems, fmt.Sprintf("TLS_MIN_VERSION: must be one of 1.0, 1.1, 1.2, 1.3 (got %q)", t.MinVersion))
+	}
+	if _, err := cipherSuiteIDs(t.CipherSuites); err != nil {
+		problems = append(problems, fmt.Sprintf("TLS_CIPHER_SUITES: %v", err))
+	}
+	if t.RedirectPort != "" && !t.Enabled() {
+		problems = append(problems, "TLS_REDIRECT_PORT: requires TLS_CERT_FILE")
+	}
+	if t.HSTSMaxAge < 0 {
+		problems = append(problems, "HSTS_MAX_AGE: must not be negative")
+	}
+	return problems
+}
+
+// cipherSuiteIDs converts cipher suite names to their IDs. Insecure suites
+// are accepted because some deployments still need them, but they must be
+// named explicitly.
+func cipherSuiteIDs(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	known := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		known[s.Name] = s.ID
+	}
+	for _, s := range tls.InsecureCipherSuites() {
+		known[s.Name] = s.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// tlsConfig builds the server's *tls.Config. Certificates are served through
+// reloader so they can be replaced without a restart.
+func (t TLSConfig) tlsConfig(reloader *certReloader) (*tls.Config, error) {
+	ids, err := cipherSuiteIDs(t.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:     tlsVersions[t.MinVersion],
+		CipherSuites:   ids,
+		GetCertificate: reloader.GetCertificate,
+	}, nil
+}
+
+// certReloader serves a certificate loaded from disk and reloads it on
+// demand, so renewed certificates are picked up on SIGHUP without dropping
+// connections.
+type certReloader struct {
+	certFile, keyFile string
+	cert              atomic.Pointer[tls.Certificate]
+}
+
+// newCertReloader loads the certificate and key, failing if they are
+// unreadable or do not match.
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload reads the certificate and key again. On failure the previous
+// certificate stays in use.
+func (r *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	r.cert.Store(&cert)
+	return nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// HSTS is middleware that sets the Strict-Transport-Security header on
+// responses to requests received over TLS, telling browsers to use HTTPS for
+// the configured period.
+func (c *Celeritas) HSTS(next http.Handler) http.Handler {
+	value := fmt.Sprintf("max-age=%d", int64(c.config.TLS.HSTSMaxAge.Seconds()))
+	if c.config.TLS.HSTSIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	if c.config.TLS.HSTSPreload {
+		value += "; preload"
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil {
+			w.Header().Set("Strict-Transport-Security", value)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// redirectHandler redirects every request to the same URL on the HTTPS port.
+func (c *Celeritas) redirectHandler() http.Handler {
+	port := c.config.Port
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[]")
+		if port != "443" {
+			host = net.JoinHostPort(host, port)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]" // IPv6 literal
+		}
+
+		// only GET and HEAD are safe to repeat after a 301
+		status := http.StatusMovedPermanently
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			status = http.StatusPermanentRedirect
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), status)
+	})
+}
+
+// createRedirectServer returns the plain-HTTP server that redirects to
+// HTTPS, or nil when TLS_REDIRECT_PORT is not set.
+func (c *Celeritas) createRedirectServer() *http.Server {
+	if c.config.TLS.RedirectPort == "" {
+		return nil
+	}
+	return &http.Server{
+		Addr:              ":" + c.config.TLS.RedirectPort,
+		ErrorLog:          c.ErrorLog,
+		Handler:           c.redirectHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       30 * time.Second,
+	}
+}
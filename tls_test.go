@@ -0,0 +1,200 @@
+package celeritas
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeSelfSignedCert writes a self-signed certificate for localhost and its
+// key to dir and returns their paths.
+func writeSelfSignedCert(t *testing.T, dir, commonName string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestTLSConfig_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     TLSConfig
+		wantErr string
+	}{
+		{"disabled", TLSConfig{MinVersion: "1.2"}, ""},
+		{"cert and key", TLSConfig{CertFile: "c", KeyFile: "k", MinVersion: "1.3"}, ""},
+		{"cert without key", TLSConfig{CertFile: "c", MinVersion: "1.2"}, "must be set together"},
+		{"bad version", TLSConfig{MinVersion: "2.0"}, "TLS_MIN_VERSION"},
+		{"unknown cipher", TLSConfig{MinVersion: "1.2", CipherSuites: []string{"TLS_FAKE"}}, "unknown cipher suite"},
+		{"redirect without tls", TLSConfig{MinVersion: "1.2", RedirectPort: "80"}, "TLS_REDIRECT_PORT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			problems := strings.Join(tt.cfg.validate(), "\n")
+			if tt.wantErr == "" && problems != "" {
+				ts.Errorf("validate() = %q, want no problems", problems)
+			}
+			if tt.wantErr != "" && !strings.Contains(problems, tt.wantErr) {
+				ts.Errorf("validate() = %q, want %q", problems, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCeleritas_createServer_TLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeSelfSignedCert(t, dir, "first")
+
+	cfg := DefaultConfig()
+	cfg.TLS.CertFile = certFile
+	cfg.TLS.KeyFile = keyFile
+	cfg.TLS.MinVersion = "1.3"
+	cfg.TLS.HSTSIncludeSubdomains = true
+
+	c, err := NewApp(WithRootPath(dir), WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("NewApp() unexpected error: %v", err)
+	}
+	c.Routes.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("secure"))
+	})
+
+	srv, err := c.createServer()
+	if err != nil {
+		t.Fatalf("createServer() unexpected error: %v", err)
+	}
+	if srv.TLSConfig == nil || srv.TLSConfig.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("createServer() TLSConfig = %+v, want TLS 1.3 minimum", srv.TLSConfig)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.ServeTLS(ln, "", "") }()
+	defer srv.Close()
+
+	var seen []string
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			seen = append(seen, cs.PeerCertificates[0].Subject.CommonName)
+			return nil
+		},
+	}}}
+	get := func() *http.Response {
+		t.Helper()
+		client.CloseIdleConnections()
+		resp, err := client.Get("https://" + ln.Addr().String() + "/")
+		if err != nil {
+			t.Fatalf("GET over TLS: %v", err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := get()
+	if got := resp.Header.Get("Strict-Transport-Security"); got != "max-age=31536000; includeSubDomains" {
+		t.Errorf("Strict-Transport-Security = %q, want one year with subdomains", got)
+	}
+
+	// replace the files and reload, as SIGHUP does
+	writeSelfSignedCert(t, dir, "second")
+	if err := c.certs.reload(); err != nil {
+		t.Fatalf("reload() unexpected error: %v", err)
+	}
+	get()
+
+	if len(seen) != 2 || seen[0] != "first" || seen[1] != "second" {
+		t.Errorf("served certificates = %v, want [first second]", seen)
+	}
+
+	// a broken file keeps the current certificate
+	if err := os.WriteFile(certFile, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.certs.reload(); err == nil {
+		t.Error("reload() of invalid certificate returned nil error")
+	}
+	get()
+	if seen[len(seen)-1] != "second" {
+		t.Errorf("served certificate after failed reload = %q, want second", seen[len(seen)-1])
+	}
+}
+
+func TestCeleritas_redirectHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     string
+		method   string
+		target   string
+		wantCode int
+		wantURL  string
+	}{
+		{"default port", "443", http.MethodGet, "http://example.com/users?page=2", http.StatusMovedPermanently, "https://example.com/users?page=2"},
+		{"custom port", "8443", http.MethodGet, "http://example.com:8080/", http.StatusMovedPermanently, "https://example.com:8443/"},
+		{"ipv6", "443", http.MethodGet, "http://[::1]:8080/", http.StatusMovedPermanently, "https://[::1]/"},
+		{"post keeps method", "443", http.MethodPost, "http://example.com/login", http.StatusPermanentRedirect, "https://example.com/login"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			c := &Celeritas{config: Config{Port: tt.port}}
+			w := httptest.NewRecorder()
+			c.redirectHandler().ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+
+			if w.Code != tt.wantCode {
+				ts.Errorf("redirect status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if got := w.Header().Get("Location"); got != tt.wantURL {
+				ts.Errorf("redirect Location = %q, want %q", got, tt.wantURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package celeritas
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgconn"
+)
+
+// Retry bounds for transactions that fail with a serialization error or a
+// deadlock.
+const (
+	txMaxAttempts  = 5
+	txInitialDelay = 10 * time.Millisecond
+	txMaxDelay     = 500 * time.Millisecond
+)
+
+// WithTx runs fn in a transaction on the primary. The transaction is
+// committed when fn returns nil and rolled back when it returns an error or
+// panics; a panic is returned as an error rather than crashing the caller.
+//
+// When the database aborts the transaction because it could not be
+// serialized or was chosen as a deadlock victim, the whole transaction is
+// retried with backoff, up to five attempts. fn may therefore run more than
+// once and must not have side effects outside the transaction.
+func (d Database) WithTx(ctx context.Context, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	if d.Pool == nil {
+		return errors.New("no database connection")
+	}
+
+	delay := txInitialDelay
+	for attempt := 1; ; attempt++ {
+		err := runTx(ctx, d.Writer(), opts, fn)
+		if err == nil || !isRetryableTxError(err) || attempt == txMaxAttempts {
+			if err != nil && attempt > 1 {
+				return fmt.Errorf("transaction failed after %d attempts: %w", attempt, err)
+			}
+			return err
+		}
+
+		// jitter keeps the transactions that collided from colliding again
+		wait := delay/2 + rand.N(delay/2+1)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		}
+		delay = min(delay*2, txMaxDelay)
+	}
+}
+
+// runTx makes a single attempt at the transaction.
+func runTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			err = fmt.Errorf("transaction panicked: %v", p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// savepointSeq numbers savepoints so nested ones never share a name.
+var savepointSeq atomic.Uint64
+
+// Savepoint runs fn inside a savepoint of tx, which may itself be inside
+// another savepoint. When fn returns an error or panics, only the work done
+// since the savepoint is rolled back and the error is returned; the
+// surrounding transaction stays usable.
+func Savepoint(ctx context.Context, tx *sql.Tx, fn func(tx *sql.Tx) error) (err error) {
+	name := fmt.Sprintf("celeritas_sp_%d", savepointSeq.Add(1))
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	rollback := func(cause error) error {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("%w (rollback to savepoint failed: %v)", cause, rbErr)
+		}
+		return cause
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = rollback(fmt.Errorf("savepoint panicked: %v", p))
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return rollback(err)
+	}
+	_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// isRetryableTxError reports whether err means the database aborted the
+// transaction and running it again may succeed: PostgreSQL serialization
+// failures (40001) and deadlocks (40P01), and MySQL deadlocks (1213).
+func isRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		return myErr.Number == 1213
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package celeritas
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgconn"
+)
+
+func newTxDatabase(t *testing.T) Database {
+	t.Helper()
+	db := openSQLite(t, "tx")
+	if _, err := db.Exec(`create table items (name text not null)`); err != nil {
+		t.Fatal(err)
+	}
+	return Database{DataType: "sqlite", Pool: db}
+}
+
+func countItems(t *testing.T, d Database) int {
+	t.Helper()
+	var n int
+	if err := d.Pool.QueryRow(`select count(*) from items`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func insertItem(tx *sql.Tx, name string) error {
+	_, err := tx.Exec(`insert into items (name) values (?)`, name)
+	return err
+}
+
+func TestDatabase_WithTx(t *testing.T) {
+	ctx := context.Background()
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name      string
+		fn        func(tx *sql.Tx) error
+		wantErr   string
+		wantCount int
+	}{
+		{"commit", func(tx *sql.Tx) error { return insertItem(tx, "a") }, "", 1},
+		{"rollback on error", func(tx *sql.Tx) error {
+			if err := insertItem(tx, "a"); err != nil {
+				return err
+			}
+			return errBoom
+		}, "boom", 0},
+		{"rollback on panic", func(tx *sql.Tx) error {
+			if err := insertItem(tx, "a"); err != nil {
+				return err
+			}
+			panic("kaboom")
+		}, "transaction panicked: kaboom", 0},
+		{"savepoint rolled back", func(tx *sql.Tx) error {
+			if err := insertItem(tx, "kept"); err != nil {
+				return err
+			}
+			err := Savepoint(ctx, tx, func(tx *sql.Tx) error {
+				if err := insertItem(tx, "discarded"); err != nil {
+					return err
+				}
+				return errBoom
+			})
+			if !errors.Is(err, errBoom) {
+				return fmt.Errorf("Savepoint() error = %v, want %v", err, errBoom)
+			}
+			return nil
+		}, "", 1},
+		{"nested savepoints", func(tx *sql.Tx) error {
+			return Savepoint(ctx, tx, func(tx *sql.Tx) error {
+				if err := insertItem(tx, "outer"); err != nil {
+					return err
+				}
+				_ = Savepoint(ctx, tx, func(tx *sql.Tx) error {
+					if err := insertItem(tx, "inner"); err != nil {
+						return err
+					}
+					panic("inner")
+				})
+				return Savepoint(ctx, tx, func(tx *sql.Tx) error { return insertItem(tx, "sibling") })
+			})
+		}, "", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			d := newTxDatabase(ts)
+			err := d.WithTx(ctx, nil, tt.fn)
+			if tt.wantErr == "" && err != nil {
+				ts.Fatalf("WithTx() unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				ts.Fatalf("WithTx() error = %v, want %q", err, tt.wantErr)
+			}
+			if got := countItems(ts, d); got != tt.wantCount {
+				ts.Errorf("WithTx() left %d rows, want %d", got, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestDatabase_WithTx_retry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("retries serialization failures", func(ts *testing.T) {
+		d := newTxDatabase(ts)
+		attempts := 0
+		err := d.WithTx(ctx, nil, func(tx *sql.Tx) error {
+			attempts++
+			if err := insertItem(tx, "a"); err != nil {
+				return err
+			}
+			if attempts < 3 {
+				return &pgconn.PgError{Code: "40001"}
+			}
+			return nil
+		})
+		if err != nil {
+			ts.Fatalf("WithTx() unexpected error: %v", err)
+		}
+		if attempts != 3 {
+			ts.Errorf("WithTx() made %d attempts, want 3", attempts)
+		}
+		if got := countItems(ts, d); got != 1 {
+			ts.Errorf("WithTx() left %d rows, want 1", got)
+		}
+	})
+
+	t.Run("gives up", func(ts *testing.T) {
+		d := newTxDatabase(ts)
+		attempts := 0
+		err := d.WithTx(ctx, nil, func(tx *sql.Tx) error {
+			attempts++
+			return &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}
+		})
+		if err == nil || !strings.Contains(err.Error(), "after 5 attempts") {
+			ts.Errorf("WithTx() error = %v, want giving up after 5 attempts", err)
+		}
+		if attempts != txMaxAttempts {
+			ts.Errorf("WithTx() made %d attempts, want %d", attempts, txMaxAttempts)
+		}
+	})
+
+	t.Run("other errors are not retried", func(ts *testing.T) {
+		d := newTxDatabase(ts)
+		attempts := 0
+		_ = d.WithTx(ctx, nil, func(tx *sql.Tx) error {
+			attempts++
+			return &pgconn.PgError{Code: "23505"}
+		})
+		if attempts != 1 {
+			ts.Errorf("WithTx() made %d attempts, want 1", attempts)
+		}
+	})
+}
+
+func TestIsRetryableTxError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, true},
+		{"postgres deadlock", fmt.Errorf("update: %w", &pgconn.PgError{Code: "40P01"}), true},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"mysql deadlock", &mysql.MySQLError{Number: 1213}, true},
+		{"mysql duplicate", &mysql.MySQLError{Number: 1062}, false},
+		{"other", errors.New("nope"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(ts *testing.T) {
+			if got := isRetryableTxError(tt.err); got != tt.want {
+				ts.Errorf("isRetryableTxError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
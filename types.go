@@ -8,29 +8,10 @@ type initPaths struct {
 	folderNames []string
 }
 
-// cookieConfig represents the configuration for the session cookie
-type cookieConfig struct {
-	name     string
-	lifetime string
-	persist  string
-	secure   string
-	domain   string
-}
-
-// databaseConfig represents the configuration for the database
-type databaseConfig struct {
-	dsn      string
-	database string
-}
-
-// Database represents the database connection
+// Database represents the database connection. Pool is the primary; use
+// Writer and Reader to route queries when read replicas are configured.
 type Database struct {
 	DataType string
 	Pool     *sql.DB
-}
-
-type redisConfig struct {
-	host     string
-	password string
-	prefix   string
+	replicas *replicaSet
 }
@@ -13,5 +13,5 @@ func (c *Celeritas) LoadTime(start time.Time) {
 	details := runtime.FuncForPC(pc).Name()
 	runTimeFunc := regexp.MustCompile(`^.*\.(.*)$`)
 	name := runTimeFunc.ReplaceAllString(details, "$1")
-	c.InfoLog.Println("This page took", elapsed, "to load", name)
+	c.logger().Info("page load time", "handler", name, "elapsed", elapsed)
 }